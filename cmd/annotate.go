@@ -0,0 +1,83 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/behnh/actions-toolkit/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+// annotateCmd represents the annotate command
+var annotateCmd = &cobra.Command{
+	Use:   "annotate",
+	Short: "Add missing version comments to SHA-pinned actions",
+	Long: `Add a version comment to every SHA-pinned GitHub Action that does not have one, without changing the pinned SHA.
+
+The comment is the most specific version tag pointing at the pinned commit (e.g. "# v4.2.3" rather than "# v4"), in the configured comment style.
+Actions that no version tag points at are left alone; run verify to check where their SHAs come from.
+With --offline, the tags are taken from the versions recorded by the lock command in .github/actions.lock instead.`,
+	Example: `  # Add version comments to the actions in a directory
+  actions-toolkit annotate --dir .github/workflows --write
+
+  # Fail CI if any SHA-pinned action is missing a version comment
+  actions-toolkit annotate --check`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		write, _ := cmd.Flags().GetBool("write")
+		check, _ := cmd.Flags().GetBool("check")
+
+		if check && write {
+			slog.Error("Cannot specify both --check and --write")
+			os.Exit(exitError)
+		}
+
+		output, err := getOutputFormat(cmd)
+		if err != nil {
+			slog.Error("Invalid output format", "error", err)
+			os.Exit(exitError)
+		}
+
+		opts, err := getProcessorOptions(cmd)
+		if err != nil {
+			slog.Error("Invalid configuration", "error", err)
+			os.Exit(exitError)
+		}
+
+		filesToProcess, err := getFilesToProcess(cmd)
+		if err != nil {
+			slog.Error("Failed to select files", "error", err)
+			os.Exit(exitError)
+		}
+
+		results := processor.AnnotateActions(filesToProcess, opts)
+		finish(output, "annotate", results, check)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(annotateCmd)
+
+	annotateCmd.Flags().String("dir", "", "Directory containing workflow files")
+	annotateCmd.Flags().String("file", "", "Specific workflow file to annotate")
+	annotateCmd.Flags().Bool("offline", false, "Look up tags in the lockfile only, without contacting GitHub")
+	annotateCmd.Flags().String("lockfile", "", "Path to the lockfile (default is .github/actions.lock in the repository root)")
+	annotateCmd.Flags().BoolP("write", "w", false, "Write changes to files (default is dry run)")
+	annotateCmd.Flags().Bool("check", false, "Exit with code 1 if any version comment would be added, without writing changes")
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the GitHub API cache",
+	Long: `Manage the on-disk cache of GitHub API responses.
+
+Release and tag lookups are cached between runs, in actions-toolkit under the user cache
+directory (e.g. ~/.cache/actions-toolkit) unless --cache-dir is given. Cached responses are
+used as they are for --cache-ttl, and revalidated with conditional requests after that,
+which do not count against the rate limit. Use --no-cache to bypass the cache.`,
+}
+
+// cacheClearCmd represents the cache clear command
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached GitHub API responses",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := getCache(cmd)
+		if err != nil {
+			slog.Error("Invalid cache configuration", "error", err)
+			os.Exit(exitError)
+		}
+
+		removed, err := c.Clear()
+		if err != nil {
+			slog.Error("Failed to clear cache", "dir", c.Dir(), "error", err)
+			os.Exit(exitError)
+		}
+		slog.Info("Cleared cache", "dir", c.Dir(), "removed", removed)
+	},
+}
+
+// cacheStatsCmd represents the cache stats command
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show statistics about the GitHub API cache",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := getCache(cmd)
+		if err != nil {
+			slog.Error("Invalid cache configuration", "error", err)
+			os.Exit(exitError)
+		}
+
+		stats, err := c.Stats()
+		if err != nil {
+			slog.Error("Failed to read cache", "dir", c.Dir(), "error", err)
+			os.Exit(exitError)
+		}
+
+		fmt.Printf("Directory: %s\n", stats.Dir)
+		fmt.Printf("TTL:       %s\n", c.TTL())
+		fmt.Printf("Entries:   %d (%d expired)\n", stats.Entries, stats.Expired)
+		fmt.Printf("Size:      %s\n", formatSize(stats.Size))
+		if stats.Entries > 0 {
+			fmt.Printf("Oldest:    %s\n", stats.Oldest.Local().Format(time.RFC3339))
+			fmt.Printf("Newest:    %s\n", stats.Newest.Local().Format(time.RFC3339))
+		}
+	},
+}
+
+// formatSize formats a size in bytes for people to read.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+}
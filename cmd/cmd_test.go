@@ -0,0 +1,313 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain runs the CLI instead of the tests when the test binary is re-executed by
+// runCLI, since commands exit the process when they finish.
+func TestMain(m *testing.M) {
+	if os.Getenv("ACTIONS_TOOLKIT_TEST_MAIN") == "1" {
+		Execute()
+		os.Exit(exitOK)
+	}
+	os.Exit(m.Run())
+}
+
+// runCLI runs the CLI in dir against the fixtures in snapshots/fixtures.yaml and
+// returns its standard output and exit code.
+func runCLI(t *testing.T, dir string, args ...string) (string, int) {
+	t.Helper()
+	fixtures, err := filepath.Abs("../snapshots/fixtures.yaml")
+	require.NoError(t, err)
+
+	args = append(args, "--fixtures", fixtures, "--no-cache")
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"ACTIONS_TOOLKIT_TEST_MAIN=1",
+		"GITHUB_TOKEN=",
+		"GH_TOKEN=",
+		"GH_CONFIG_DIR="+t.TempDir(),
+		"XDG_CACHE_HOME="+t.TempDir(),
+	)
+
+	output, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return string(output), exitErr.ExitCode()
+	}
+	require.NoError(t, err)
+	return string(output), exitOK
+}
+
+// writeWorkflow writes a workflow to .github/workflows/ci.yml in a new repository and
+// returns the repository root and the workflow path.
+func writeWorkflow(t *testing.T, content string) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+	workflows := filepath.Join(root, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(workflows, 0755))
+	path := filepath.Join(workflows, "ci.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return root, path
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(content)
+}
+
+const unpinnedWorkflow = `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/setup-node@v3
+      - uses: actions/cache@v4
+`
+
+// pinnedWorkflow is unpinnedWorkflow after pinning, which keeps each action on the
+// commit its tag points at
+const pinnedWorkflow = `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/setup-node@3235b876344d2a9aa001b8d1453c930bba69e610 # v3.9.1
+      - uses: actions/cache@5a3ec84eff668545956fd18022155c47e93e2684 # v4.2.3
+`
+
+func TestPinCommand(t *testing.T) {
+	root, path := writeWorkflow(t, unpinnedWorkflow)
+
+	_, code := runCLI(t, root, "pin", "--all", "--check", "--dir", ".github/workflows")
+	assert.Equal(t, exitChanges, code)
+	assert.Equal(t, unpinnedWorkflow, readFile(t, path))
+
+	_, code = runCLI(t, root, "pin", "--all", "--write", "--dir", ".github/workflows")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, pinnedWorkflow, readFile(t, path))
+
+	// Pinning again changes nothing, although v3.9.1 is not the latest release
+	_, code = runCLI(t, root, "pin", "--all", "--check", "--dir", ".github/workflows")
+	assert.Equal(t, exitOK, code)
+}
+
+func TestPinCommandUpdatePinned(t *testing.T) {
+	root, path := writeWorkflow(t, pinnedWorkflow)
+
+	// Release age only decides where pinned actions move to when they are updated
+	_, code := runCLI(t, root, "pin", "--all", "--min-age", "7d", "--dir", ".github/workflows")
+	assert.Equal(t, exitError, code)
+
+	_, code = runCLI(t, root, "pin", "--all", "--update-pinned", "--min-age", "7d", "--write", "--dir", ".github/workflows")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, readFile(t, path), "actions/setup-node@49933ea5288caeca8642d1e84afbd3f7d6820020 # v4.4.0\n")
+	assert.Contains(t, readFile(t, path), "actions/cache@5a3ec84eff668545956fd18022155c47e93e2684 # v4.2.3\n")
+}
+
+func TestPinCommandUnknownAction(t *testing.T) {
+	root, _ := writeWorkflow(t, `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: octo-org/unknown@v1
+`)
+
+	_, code := runCLI(t, root, "pin", "--all", "--dir", ".github/workflows")
+	assert.Equal(t, exitError, code)
+}
+
+func TestUpdateCommand(t *testing.T) {
+	root, path := writeWorkflow(t, `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/setup-node@3235b876344d2a9aa001b8d1453c930bba69e610 # v3.9.1
+`)
+
+	_, code := runCLI(t, root, "update", "--action", "actions/setup-node", "--strategy", "minor", "--write", "--file", path)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, readFile(t, path), "actions/setup-node@3235b876344d2a9aa001b8d1453c930bba69e610 # v3.9.1")
+
+	_, code = runCLI(t, root, "update", "--action", "actions/setup-node", "--write", "--file", path)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, readFile(t, path), "actions/setup-node@49933ea5288caeca8642d1e84afbd3f7d6820020 # v4.4.0")
+}
+
+func TestVerifyCommand(t *testing.T) {
+	root, _ := writeWorkflow(t, `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/setup-node@49933ea5288caeca8642d1e84afbd3f7d6820020 # v4.3.0
+      - uses: actions/cache@5a3ec84eff668545956fd18022155c47e93e2684 # v4.2.3
+`)
+
+	output, code := runCLI(t, root, "verify", "--dir", ".github/workflows", "--output", "json")
+	assert.Equal(t, exitChanges, code)
+
+	var report struct {
+		Command string
+		Results []struct {
+			Action string
+			Status string
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &report))
+	assert.Equal(t, "verify", report.Command)
+	statuses := make(map[string]string)
+	for _, result := range report.Results {
+		statuses[result.Action] = result.Status
+	}
+	assert.Equal(t, map[string]string{
+		"actions/setup-node": "mismatch",
+		"actions/cache":      "ok",
+	}, statuses)
+}
+
+func TestLockFromSubdirectory(t *testing.T) {
+	root, path := writeWorkflow(t, unpinnedWorkflow)
+	for _, dir := range []string{"sub", "other"} {
+		require.NoError(t, os.Mkdir(filepath.Join(root, dir), 0755))
+	}
+
+	// The lockfile is written to the repository root, wherever lock runs
+	_, code := runCLI(t, filepath.Join(root, "sub"), "lock", "--dir", "../.github/workflows")
+	assert.Equal(t, exitOK, code)
+	assert.FileExists(t, filepath.Join(root, ".github", "actions.lock"))
+	assert.NoFileExists(t, filepath.Join(root, "sub", ".github", "actions.lock"))
+
+	_, code = runCLI(t, filepath.Join(root, "other"), "pin", "--all", "--offline", "--write", "--dir", "../.github/workflows")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, pinnedWorkflow, readFile(t, path))
+}
+
+func TestAnnotateCommand(t *testing.T) {
+	root, path := writeWorkflow(t, `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/cache/save@5a3ec84eff668545956fd18022155c47e93e2684
+`)
+
+	_, code := runCLI(t, root, "annotate", "--dir", ".github/workflows", "--check")
+	assert.Equal(t, exitChanges, code)
+
+	_, code = runCLI(t, root, "annotate", "--dir", ".github/workflows", "--write")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, readFile(t, path), "actions/cache/save@5a3ec84eff668545956fd18022155c47e93e2684 # v4.2.3\n")
+
+	_, code = runCLI(t, root, "annotate", "--dir", ".github/workflows", "--check")
+	assert.Equal(t, exitOK, code)
+}
+
+func TestScanCommandSARIF(t *testing.T) {
+	root, _ := writeWorkflow(t, unpinnedWorkflow)
+
+	output, code := runCLI(t, root, "scan", "--dir", ".github/workflows", "--output", "sarif")
+	assert.Equal(t, exitChanges, code)
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Results []json.RawMessage
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	assert.Len(t, log.Runs[0].Results, 2)
+}
+
+func TestScanCommandOutdatedPin(t *testing.T) {
+	root, _ := writeWorkflow(t, pinnedWorkflow)
+
+	output, code := runCLI(t, root, "scan", "--dir", ".github/workflows", "--output", "sarif")
+	assert.Equal(t, exitChanges, code)
+
+	var log struct {
+		Runs []struct {
+			Results []struct {
+				RuleID string
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &log))
+	require.Len(t, log.Runs, 1)
+	require.Len(t, log.Runs[0].Results, 1)
+	assert.Equal(t, "outdated-pin", log.Runs[0].Results[0].RuleID)
+}
+
+func TestLockAndPinOffline(t *testing.T) {
+	root, path := writeWorkflow(t, unpinnedWorkflow)
+
+	_, code := runCLI(t, root, "lock", "--dir", ".github/workflows")
+	assert.Equal(t, exitOK, code)
+	assert.FileExists(t, filepath.Join(root, ".github", "actions.lock"))
+
+	_, code = runCLI(t, root, "pin", "--all", "--offline", "--write", "--dir", ".github/workflows")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, pinnedWorkflow, readFile(t, path))
+}
+
+func TestLockAndVerifyOfflineWrongComment(t *testing.T) {
+	// cdca7365 is the commit of v4.3.0, not v4.4.0
+	root, path := writeWorkflow(t, `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/setup-node@cdca7365b2dadb8aad0a33bc7601856ffabcc48e # v4.4.0
+`)
+
+	_, code := runCLI(t, root, "lock", "--dir", ".github/workflows")
+	assert.Equal(t, exitOK, code)
+
+	_, code = runCLI(t, root, "verify", "--offline", "--dir", ".github/workflows")
+	assert.Equal(t, exitChanges, code)
+
+	// Pinning v4.4.0 from the lockfile uses its own commit, not the mislabelled one
+	require.NoError(t, os.WriteFile(path, []byte(`on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/setup-node@v4.4.0
+`), 0644))
+	_, code = runCLI(t, root, "pin", "--all", "--offline", "--write", "--dir", ".github/workflows")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, readFile(t, path), "actions/setup-node@49933ea5288caeca8642d1e84afbd3f7d6820020 # v4.4.0\n")
+}
@@ -0,0 +1,239 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/behnh/actions-toolkit/internal/cache"
+	"github.com/behnh/actions-toolkit/internal/config"
+	"github.com/behnh/actions-toolkit/internal/fixtures"
+	"github.com/behnh/actions-toolkit/internal/github"
+	"github.com/behnh/actions-toolkit/internal/lockfile"
+	"github.com/behnh/actions-toolkit/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+// cfg is the project configuration, loaded before any command runs.
+var cfg = &config.Config{}
+
+// loadConfig loads the configuration given by --config, or discovers it in the root
+// of the repository, and applies its defaults to flags that were not set.
+func loadConfig(cmd *cobra.Command) error {
+	path, _ := cmd.Flags().GetString("config")
+	loaded, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	cfg = loaded
+
+	if cfg.Output != "" && !cmd.Flags().Changed("output") {
+		if err := cmd.Flags().Set("output", cfg.Output); err != nil {
+			return fmt.Errorf("invalid output format in %s: %w", cfg.Path, err)
+		}
+	}
+	return nil
+}
+
+// configureGitHub sets the GitHub instances that actions are resolved against, from
+// --github-url or the configuration.
+func configureGitHub(cmd *cobra.Command) error {
+	githubURL := cfg.GitHubURL
+	if flag := cmd.Flags().Lookup("github-url"); flag != nil && flag.Changed {
+		githubURL = flag.Value.String()
+	}
+	if err := github.SetDefaultURL(githubURL); err != nil {
+		return err
+	}
+
+	for owner, ownerURL := range cfg.GitHubOwners {
+		if err := github.SetOwnerURL(owner, ownerURL); err != nil {
+			return fmt.Errorf("owner %s: %w", owner, err)
+		}
+	}
+
+	return configureGitHubApp(cmd)
+}
+
+// configureGitHubApp sets up GitHub App authentication if --app-id is given.
+func configureGitHubApp(cmd *cobra.Command) error {
+	appID, _ := cmd.Flags().GetInt64("app-id")
+	installationID, _ := cmd.Flags().GetInt64("app-installation-id")
+	keyPath, _ := cmd.Flags().GetString("app-private-key")
+	token, _ := cmd.Flags().GetString("token")
+
+	if appID == 0 && installationID == 0 && keyPath == "" {
+		return nil
+	}
+	if appID == 0 || installationID == 0 || keyPath == "" {
+		return errors.New("--app-id, --app-installation-id and --app-private-key must be used together")
+	}
+	if token != "" {
+		return errors.New("cannot specify both --token and GitHub App authentication")
+	}
+
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
+	return github.SetApp(appID, installationID, key)
+}
+
+// getCache returns the GitHub API cache selected by --cache-dir and --cache-ttl or the
+// configuration, in the user's cache directory by default.
+func getCache(cmd *cobra.Command) (*cache.Cache, error) {
+	dir := cfg.CacheDir
+	if dir != "" && !filepath.IsAbs(dir) {
+		dir = filepath.Join(cfg.Root, dir)
+	}
+	if flag := cmd.Flags().Lookup("cache-dir"); flag != nil && flag.Changed {
+		dir = flag.Value.String()
+	}
+	if dir == "" {
+		var err error
+		if dir, err = cache.DefaultDir(); err != nil {
+			return nil, fmt.Errorf("failed to find the cache directory, set one with --cache-dir: %w", err)
+		}
+	}
+
+	ttl := cache.DefaultTTL
+	ttlValue := cfg.CacheTTL
+	if flag := cmd.Flags().Lookup("cache-ttl"); flag != nil && flag.Changed {
+		ttlValue = flag.Value.String()
+	}
+	if ttlValue != "" {
+		var err error
+		if ttl, err = time.ParseDuration(ttlValue); err != nil || ttl < 0 {
+			return nil, fmt.Errorf("invalid cache TTL %q, expected a duration such as 1h", ttlValue)
+		}
+	}
+
+	return cache.New(dir, ttl), nil
+}
+
+// configureCache enables the GitHub API cache, unless --no-cache is given.
+func configureCache(cmd *cobra.Command) error {
+	if noCache, _ := cmd.Flags().GetBool("no-cache"); noCache {
+		github.SetCache("", 0)
+		return nil
+	}
+	c, err := getCache(cmd)
+	if err != nil {
+		return err
+	}
+	github.SetCache(c.Dir(), c.TTL())
+	return nil
+}
+
+// getLockfilePath returns the lockfile selected by --lockfile, in the root of the
+// repository by default.
+func getLockfilePath(cmd *cobra.Command) string {
+	if path, _ := cmd.Flags().GetString("lockfile"); path != "" {
+		return path
+	}
+	return filepath.Join(cfg.Root, lockfile.DefaultPath)
+}
+
+// getProcessorOptions returns the processor options for a command, combining its
+// flags with the project configuration.
+func getProcessorOptions(cmd *cobra.Command) (processor.Options, error) {
+	token, _ := cmd.Flags().GetString("token")
+	write, _ := cmd.Flags().GetBool("write")
+
+	style, err := processor.ParseCommentStyle(cfg.CommentStyle)
+	if err != nil {
+		return processor.Options{}, err
+	}
+
+	// --strategy, where a command has it, takes precedence over the configured default
+	strategyName := cfg.Strategy
+	if flag := cmd.Flags().Lookup("strategy"); flag != nil && flag.Changed {
+		strategyName = flag.Value.String()
+	}
+	strategy, err := processor.ParseStrategy(strategyName)
+	if err != nil {
+		return processor.Options{}, err
+	}
+
+	// Likewise for --min-age
+	minAgeValue := cfg.MinAge
+	if flag := cmd.Flags().Lookup("min-age"); flag != nil && flag.Changed {
+		minAgeValue = flag.Value.String()
+	}
+	minAge, err := processor.ParseMinAge(minAgeValue)
+	if err != nil {
+		return processor.Options{}, err
+	}
+
+	// And for --allow-prerelease
+	allowPrerelease := cfg.AllowPrerelease
+	if flag := cmd.Flags().Lookup("allow-prerelease"); flag != nil && flag.Changed {
+		allowPrerelease, _ = cmd.Flags().GetBool("allow-prerelease")
+	}
+
+	strategies := make(map[string]processor.Strategy)
+	for action, name := range cfg.Strategies() {
+		strategies[action], err = processor.ParseStrategy(name)
+		if err != nil {
+			return processor.Options{}, fmt.Errorf("action %s: %w", action, err)
+		}
+	}
+
+	concurrency := cfg.Concurrency
+	if flag := cmd.Flags().Lookup("concurrency"); flag != nil && flag.Changed {
+		concurrency, _ = cmd.Flags().GetInt("concurrency")
+	}
+	if concurrency < 0 {
+		return processor.Options{}, fmt.Errorf("invalid concurrency %d, expected a positive number", concurrency)
+	}
+
+	// --offline, where a command has it, resolves actions from the lockfile only
+	var lock *lockfile.Lockfile
+	if offline, _ := cmd.Flags().GetBool("offline"); offline {
+		if lock, err = lockfile.Load(getLockfilePath(cmd)); err != nil {
+			return processor.Options{}, err
+		}
+	}
+
+	// --fixtures resolves actions from a fixture file instead of GitHub, for tests
+	var resolver processor.Resolver
+	if path, _ := cmd.Flags().GetString("fixtures"); path != "" {
+		if resolver, err = fixtures.Load(path); err != nil {
+			return processor.Options{}, err
+		}
+	}
+
+	return processor.Options{
+		Token:           token,
+		Write:           write,
+		Ignore:          cfg.Ignore,
+		Versions:        cfg.Versions(),
+		TagPrefixes:     cfg.TagPrefixes(),
+		Strategy:        strategy,
+		Strategies:      strategies,
+		MinAge:          minAge,
+		AllowPrerelease: allowPrerelease,
+		CommentStyle:    style,
+		Lock:            lock,
+		Concurrency:     concurrency,
+		Resolver:        resolver,
+	}, nil
+}
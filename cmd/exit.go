@@ -0,0 +1,66 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/behnh/actions-toolkit/internal/processor"
+)
+
+// Exit codes, so that CI can tell findings apart from failures without parsing logs.
+const (
+	exitOK      = 0 // Everything is pinned and up to date
+	exitChanges = 1 // Changes would be made (with --check), or verification found problems
+	exitError   = 2 // Actions could not be resolved, or the command could not run
+)
+
+// exitCode returns the exit code for a run. Pending changes only fail the run in
+// check mode.
+func exitCode(summary processor.Summary, check bool) int {
+	switch {
+	case summary.Failed > 0:
+		return exitError
+	case summary.Problems > 0:
+		return exitChanges
+	case check && summary.Changed > 0:
+		return exitChanges
+	default:
+		return exitOK
+	}
+}
+
+// finish writes the report for a run, logs its outcome and exits with the matching code.
+func finish(format string, command string, results []processor.Result, check bool) {
+	if err := writeReport(format, command, results); err != nil {
+		slog.Error("Failed to write report", "error", err)
+		os.Exit(exitError)
+	}
+
+	summary := processor.Summarize(results)
+	code := exitCode(summary, check)
+	switch {
+	case summary.Failed > 0:
+		slog.Error("Finished with errors", "changes", summary.Changed, "problems", summary.Problems, "errors", summary.Failed)
+	case summary.Problems > 0:
+		slog.Error("Found problems", "problems", summary.Problems, "checked", summary.Total)
+	case code == exitChanges:
+		slog.Warn("Check failed, changes are needed", "changes", summary.Changed, "hint", "Run pin or update with --write to apply them")
+	}
+	os.Exit(code)
+}
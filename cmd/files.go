@@ -0,0 +1,69 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/behnh/actions-toolkit/internal/file"
+	"github.com/spf13/cobra"
+)
+
+// getFilesToProcess returns the workflow files selected by a command's --dir and --file
+// flags, or by the include globs of the configuration if neither is given. Files in a
+// directory that match an exclude glob are left out.
+func getFilesToProcess(cmd *cobra.Command) ([]string, error) {
+	dirPath, _ := cmd.Flags().GetString("dir")
+	filePath, _ := cmd.Flags().GetString("file")
+
+	if dirPath != "" && filePath != "" {
+		return nil, errors.New("cannot specify both --dir and --file")
+	}
+
+	if filePath != "" {
+		return []string{filePath}, nil
+	}
+
+	if dirPath != "" {
+		files, err := file.GetYAMLFiles(dirPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get YAML files: %w", err)
+		}
+
+		var included []string
+		for _, f := range files {
+			if cfg.Excluded(f) {
+				slog.Debug("Skipping excluded file", "file", f)
+				continue
+			}
+			included = append(included, f)
+		}
+		return included, nil
+	}
+
+	if cfg.Path != "" {
+		files, err := cfg.Files()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get files from configuration: %w", err)
+		}
+		return files, nil
+	}
+
+	return nil, errors.New("either --dir or --file must be specified, or a configuration file must exist")
+}
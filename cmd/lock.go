@@ -0,0 +1,81 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/behnh/actions-toolkit/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+// lockCmd represents the lock command
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Record the resolved versions and commit SHAs of actions in a lockfile",
+	Long: `Resolve every action in the workflow files the way pin --all would, and record each ref's
+version and commit SHA in a lockfile (.github/actions.lock by default). The version comments
+of SHA-pinned actions are recorded as well.
+
+Commit the lockfile so that pin --offline and verify --offline can run without access to
+GitHub, e.g. on air-gapped build agents. Run lock again whenever workflows change; the
+lockfile is rewritten from scratch, and left alone if any action cannot be resolved.`,
+	Example: `  # Refresh the lockfile for the workflows in .github/workflows
+  actions-toolkit lock --dir .github/workflows`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, err := getOutputFormat(cmd)
+		if err != nil {
+			slog.Error("Invalid output format", "error", err)
+			os.Exit(exitError)
+		}
+
+		opts, err := getProcessorOptions(cmd)
+		if err != nil {
+			slog.Error("Invalid configuration", "error", err)
+			os.Exit(exitError)
+		}
+
+		filesToProcess, err := getFilesToProcess(cmd)
+		if err != nil {
+			slog.Error("Failed to select files", "error", err)
+			os.Exit(exitError)
+		}
+
+		lock, results := processor.LockActions(filesToProcess, opts)
+		path := getLockfilePath(cmd)
+		if summary := processor.Summarize(results); summary.Failed > 0 {
+			slog.Error("Not writing lockfile, some actions could not be resolved", "lockfile", path, "errors", summary.Failed)
+		} else if err := lock.Save(path); err != nil {
+			slog.Error("Failed to write lockfile", "lockfile", path, "error", err)
+			os.Exit(exitError)
+		} else {
+			slog.Info("Wrote lockfile", "lockfile", path, "actions", len(lock.Actions))
+		}
+
+		finish(output, "lock", results, false)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+
+	lockCmd.Flags().String("dir", "", "Directory containing workflow files")
+	lockCmd.Flags().String("file", "", "Specific workflow file to lock")
+	lockCmd.Flags().String("lockfile", "", "Path to the lockfile (default is .github/actions.lock in the repository root)")
+}
@@ -0,0 +1,57 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/behnh/actions-toolkit/internal/processor"
+	"github.com/behnh/actions-toolkit/internal/report"
+	"github.com/spf13/cobra"
+)
+
+// Output formats supported by --output
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputSARIF = "sarif"
+)
+
+// getOutputFormat returns the format selected by --output.
+func getOutputFormat(cmd *cobra.Command) (string, error) {
+	output, _ := cmd.Flags().GetString("output")
+	switch output {
+	case outputText, outputJSON, outputSARIF:
+		return output, nil
+	default:
+		return "", fmt.Errorf("unsupported output format %q", output)
+	}
+}
+
+// writeReport writes the results of a run to stdout in the given format. Text output
+// is already covered by the log, so nothing extra is written for it.
+func writeReport(format string, command string, results []processor.Result) error {
+	switch format {
+	case outputJSON:
+		return report.WriteJSON(os.Stdout, command, results)
+	case outputSARIF:
+		return report.WriteSARIF(os.Stdout, version, results)
+	default:
+		return nil
+	}
+}
@@ -17,18 +17,26 @@ limitations under the License.
 package cmd
 
 import (
-	"github.com/behnh/actions-toolkit/internal/file"
+	"log/slog"
+	"os"
+
 	"github.com/behnh/actions-toolkit/internal/processor"
 	"github.com/spf13/cobra"
-	"log/slog"
 )
 
 var pinCmd = &cobra.Command{
 	Use:   "pin",
 	Short: "Pin GitHub Actions to a specific version using release commit SHAs",
 	Long: `Pin GitHub Actions to a specific version using release commit SHAs. This satisfies GitHub's recommended best practices for Actions security, as detailed here:
-https://docs.github.com/en/actions/security-for-github-actions/security-guides/security-hardening-for-github-actions#using-third-party-actions`,
-	Example: `  # Pin all actions to the latest release in a directory
+https://docs.github.com/en/actions/security-for-github-actions/security-guides/security-hardening-for-github-actions#using-third-party-actions
+
+With --all, tags and branches (e.g. v3, v4.2 or main) are pinned to the commit they point at now, with the most specific version tag of that commit as the comment, so pinning never changes which code runs.
+Actions that are already pinned to a SHA are left alone, so pinning again changes nothing.
+With --update-pinned, they are moved to the latest release instead, or with --min-age to the newest release that has been published for at least that long.
+Prereleases are only adopted with --allow-prerelease, or for actions that are already on a prerelease of the same version.
+Container images used by docker:// steps, job containers and service containers are also pinned to the digest of their current tag.
+With --offline, actions are pinned to the commits recorded by the lock command in .github/actions.lock instead, and images are left alone.`,
+	Example: `  # Pin all actions in a directory to the commits their tags point at
   actions-toolkit pin --all --dir .github/workflows --write
 
   # Pin a specific action to a specific version in a file
@@ -36,56 +44,79 @@ https://docs.github.com/en/actions/security-for-github-actions/security-guides/s
 
   # Pin a specific action to a version in a directory
   actions-toolkit pin --action actions/checkout --version v4.2.2 --dir .github/workflows --write
+
+  # Pin the files listed in .actions-toolkit.yaml
+  actions-toolkit pin --all --write
+
+  # Also move actions pinned to a SHA to the newest release that is at least a week old
+  actions-toolkit pin --all --update-pinned --min-age 7d --write
+
+  # Fail CI if any action is not pinned (exit code 1), or cannot be resolved (exit code 2)
+  actions-toolkit pin --all --dir .github/workflows --check
+
+  # Pin from the lockfile written by the lock command, without access to GitHub
+  actions-toolkit pin --all --offline --write
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		actionName, _ := cmd.Flags().GetString("action")
 		version, _ := cmd.Flags().GetString("version")
 		all, _ := cmd.Flags().GetBool("all")
-		dirPath, _ := cmd.Flags().GetString("dir")
-		filePath, _ := cmd.Flags().GetString("file")
 		write, _ := cmd.Flags().GetBool("write")
-		token, _ := cmd.Flags().GetString("token")
+		check, _ := cmd.Flags().GetBool("check")
+		updatePinned, _ := cmd.Flags().GetBool("update-pinned")
 
 		if all && (actionName != "" || version != "") {
 			slog.Error("Cannot specify both --all and --action or --version")
-			return
+			os.Exit(exitError)
 		}
 
 		if !all && (actionName == "" || version == "") {
 			slog.Error("Must specify --action and --version when --all is not provided")
-			return
+			os.Exit(exitError)
 		}
 
-		if dirPath != "" && filePath != "" {
-			slog.Error("Cannot specify both --dir and --file")
-			return
+		if updatePinned && !all {
+			slog.Error("Cannot specify --update-pinned without --all")
+			os.Exit(exitError)
 		}
 
-		var filesToProcess []string
-		var err error
-
-		if filePath != "" {
-			filesToProcess = []string{filePath}
-		} else if dirPath != "" {
-			filesToProcess, err = file.GetYAMLFiles(dirPath)
-			if err != nil {
-				slog.Error("Failed to get YAML files", "error", err)
-				return
-			}
-		} else {
-			slog.Error("Either --dir or --file must be specified")
-			return
+		if !updatePinned && (cmd.Flags().Changed("min-age") || cmd.Flags().Changed("allow-prerelease")) {
+			slog.Error("Cannot specify --min-age or --allow-prerelease without --update-pinned")
+			os.Exit(exitError)
 		}
 
-		// result := processor.FindActionsInFiles(filesToProcess)
-		// slog.Info("Found the following actions to pin", "actions", result, "count", len(result))
+		if check && write {
+			slog.Error("Cannot specify both --check and --write")
+			os.Exit(exitError)
+		}
+
+		output, err := getOutputFormat(cmd)
+		if err != nil {
+			slog.Error("Invalid output format", "error", err)
+			os.Exit(exitError)
+		}
+
+		opts, err := getProcessorOptions(cmd)
+		if err != nil {
+			slog.Error("Invalid configuration", "error", err)
+			os.Exit(exitError)
+		}
+		opts.UpdatePinned = updatePinned
+
+		filesToProcess, err := getFilesToProcess(cmd)
+		if err != nil {
+			slog.Error("Failed to select files", "error", err)
+			os.Exit(exitError)
+		}
 
+		var results []processor.Result
 		if all {
-			processor.PinAllActions(filesToProcess, token, write)
+			results = processor.PinAllActions(filesToProcess, opts)
 		} else {
-			processor.PinAction(filesToProcess, actionName, version, token, write)
+			results = processor.PinAction(filesToProcess, actionName, version, opts)
 		}
 
+		finish(output, "pin", results, check)
 	},
 }
 
@@ -94,8 +125,14 @@ func init() {
 
 	pinCmd.Flags().String("action", "", "Action name to pin (required if --all is not specified)")
 	pinCmd.Flags().String("version", "", "Version to pin to (required if --all is not specified)")
-	pinCmd.Flags().BoolP("all", "a", false, "Pin all actions to the latest release")
+	pinCmd.Flags().BoolP("all", "a", false, "Pin all actions to the commit their ref points at")
 	pinCmd.Flags().String("dir", "", "Directory containing workflow files")
 	pinCmd.Flags().String("file", "", "Specific workflow file to pin")
+	pinCmd.Flags().Bool("update-pinned", false, "Also move actions that are already pinned to a SHA to the latest release")
+	pinCmd.Flags().String("min-age", "", "With --update-pinned, only adopt releases published at least this long ago (e.g. 7d, 2w, 36h)")
+	pinCmd.Flags().Bool("allow-prerelease", false, "With --update-pinned, adopt prereleases as well as releases")
+	pinCmd.Flags().Bool("offline", false, "Resolve actions from the lockfile only, without contacting GitHub")
+	pinCmd.Flags().String("lockfile", "", "Path to the lockfile (default is .github/actions.lock in the repository root)")
 	pinCmd.Flags().BoolP("write", "w", false, "Write changes to files (default is dry run)")
+	pinCmd.Flags().Bool("check", false, "Exit with code 1 if any action would be pinned, without writing changes")
 }
@@ -20,6 +20,7 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/behnh/actions-toolkit/internal/processor"
 	"github.com/spf13/cobra"
 )
 
@@ -35,6 +36,24 @@ var rootCmd = &cobra.Command{
 				Level: slog.LevelDebug,
 			})))
 		}
+
+		if err := loadConfig(cmd); err != nil {
+			slog.Error("Failed to load configuration", "error", err)
+			os.Exit(exitError)
+		}
+		if cfg.Path != "" {
+			slog.Debug("Loaded configuration", "path", cfg.Path)
+		}
+
+		if err := configureGitHub(cmd); err != nil {
+			slog.Error("Invalid GitHub configuration", "error", err)
+			os.Exit(exitError)
+		}
+
+		if err := configureCache(cmd); err != nil {
+			slog.Error("Invalid cache configuration", "error", err)
+			os.Exit(exitError)
+		}
 	},
 }
 
@@ -43,15 +62,29 @@ var rootCmd = &cobra.Command{
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 }
 
 func init() {
 	flags := rootCmd.PersistentFlags()
 	flags.Bool("debug", false, "Enable debug logging")
-	flags.String("token", "", "GitHub token to use for authentication")
+	flags.String("config", "", "Path to the configuration file (default is .actions-toolkit.yaml in the repository root)")
+	flags.String("token", "", "GitHub token for the default GitHub instance (default is GITHUB_TOKEN, GH_TOKEN or the gh CLI login)")
+	flags.String("github-url", "", "URL of the GitHub instance to resolve actions against, e.g. a GitHub Enterprise Server (default is github.com)")
+	flags.Int64("app-id", 0, "ID of a GitHub App to authenticate as, instead of a token")
+	flags.Int64("app-installation-id", 0, "ID of the GitHub App installation to authenticate as")
+	flags.String("app-private-key", "", "Path to the private key (PEM) of the GitHub App")
+	flags.String("cache-dir", "", "Directory to cache GitHub API responses in (default is actions-toolkit in the user cache directory)")
+	flags.String("cache-ttl", "", "How long cached GitHub API responses are used before revalidating them (default 1h)")
+	flags.Bool("no-cache", false, "Do not cache GitHub API responses on disk")
+	flags.IntP("concurrency", "j", processor.DefaultConcurrency, "Number of actions to resolve at the same time")
 	flags.BoolP("write", "w", false, "Write changes to file(s)")
+	flags.StringP("output", "o", outputText, "Output format for results: text, json or sarif")
+
+	// Resolves actions from a fixture file instead of GitHub, for end-to-end tests
+	flags.String("fixtures", "", "Path to a fixture file to resolve actions and images from instead of the network")
+	flags.MarkHidden("fixtures")
 
 	rootCmd.SetVersionTemplate("{{.Name}} version {{.Version}}+" + gitCommit + "\n")
 }
@@ -0,0 +1,70 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/behnh/actions-toolkit/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+// scanCmd represents the scan command
+var scanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Report unpinned, outdated and mismatched actions without changing files",
+	Long: `Scan workflow files for actions that are not pinned to a commit SHA, images that are not
+pinned to a digest, pinned SHAs that are out of date, and pinned SHAs that do not match their
+version comment. Files are never changed.
+
+Use --output sarif to upload the findings to GitHub code scanning, which annotates the exact
+'uses:' line in pull requests. Exits with code 1 if anything is found, or code 2 if an action
+could not be resolved.`,
+	Example: `  # Scan workflows and write a SARIF log for code scanning
+  actions-toolkit scan --dir .github/workflows --output sarif > actions-toolkit.sarif`,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, err := getOutputFormat(cmd)
+		if err != nil {
+			slog.Error("Invalid output format", "error", err)
+			os.Exit(exitError)
+		}
+
+		opts, err := getProcessorOptions(cmd)
+		if err != nil {
+			slog.Error("Invalid configuration", "error", err)
+			os.Exit(exitError)
+		}
+
+		filesToProcess, err := getFilesToProcess(cmd)
+		if err != nil {
+			slog.Error("Failed to select files", "error", err)
+			os.Exit(exitError)
+		}
+
+		results := processor.ScanActions(filesToProcess, opts)
+
+		finish(output, "scan", results, true)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanCmd)
+
+	scanCmd.Flags().String("dir", "", "Directory containing workflow files")
+	scanCmd.Flags().String("file", "", "Specific workflow file to scan")
+}
@@ -17,10 +17,11 @@ limitations under the License.
 package cmd
 
 import (
-	"github.com/behnh/actions-toolkit/internal/file"
+	"log/slog"
+	"os"
+
 	"github.com/behnh/actions-toolkit/internal/processor"
 	"github.com/spf13/cobra"
-	"log/slog"
 )
 
 // updateCmd represents the update command
@@ -28,38 +29,65 @@ var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update GitHub Actions to their latest versions",
 	Long: `Update GitHub Actions to their latest versions in workflow files.
-You can specify a specific action to update, or update all actions in a file or directory.`,
+You can specify a specific action to update, or update all actions in a file or directory.
+
+The --strategy flag limits how far an action may move from its current version:
+  major  move to the latest release, even across major versions (default)
+  minor  stay in the current major version
+  patch  stay in the current minor version
+For SHA-pinned actions, the current version is read from the version comment. Per-action
+strategies can be set in .actions-toolkit.yaml and take precedence over --strategy.
+
+With --min-age, releases published more recently than the given age (e.g. 7d) are not
+adopted, and the newest release that is old enough is used instead.
+
+Prereleases (e.g. v5.0.0-beta.1, or releases marked as prereleases on GitHub) are only
+adopted with --allow-prerelease, or if the current version is a prerelease of the same
+version, which moves on to newer prereleases and finally the release itself.`,
+	Example: `  # Update actions/cache without leaving its current major version
+  actions-toolkit update --action actions/cache --dir .github/workflows --strategy minor --write
+
+  # Only adopt releases that have been published for at least a week
+  actions-toolkit update --action actions/cache --dir .github/workflows --min-age 7d --write`,
 	Run: func(cmd *cobra.Command, args []string) {
 		actionName, _ := cmd.Flags().GetString("action")
-		dirPath, _ := cmd.Flags().GetString("dir")
-		filePath, _ := cmd.Flags().GetString("file")
 		write, _ := cmd.Flags().GetBool("write")
-		token, _ := cmd.Flags().GetString("token")
+		check, _ := cmd.Flags().GetBool("check")
 
 		if actionName == "" {
 			slog.Error("Action name is required")
-			return
+			os.Exit(exitError)
 		}
 
-		var filesToProcess []string
-		var err error
-
-		if filePath != "" {
-			filesToProcess = []string{filePath}
-		} else if dirPath != "" {
-			filesToProcess, err = file.GetYAMLFiles(dirPath)
-			if err != nil {
-				slog.Error("Failed to get YAML files", "error", err)
-				return
-			}
-		} else {
-			slog.Error("Either --dir or --file must be specified")
-			return
+		if check && write {
+			slog.Error("Cannot specify both --check and --write")
+			os.Exit(exitError)
 		}
 
+		output, err := getOutputFormat(cmd)
+		if err != nil {
+			slog.Error("Invalid output format", "error", err)
+			os.Exit(exitError)
+		}
+
+		opts, err := getProcessorOptions(cmd)
+		if err != nil {
+			slog.Error("Invalid configuration", "error", err)
+			os.Exit(exitError)
+		}
+
+		filesToProcess, err := getFilesToProcess(cmd)
+		if err != nil {
+			slog.Error("Failed to select files", "error", err)
+			os.Exit(exitError)
+		}
+
+		var results []processor.Result
 		for _, f := range filesToProcess {
-			processor.UpdateAction(f, actionName, token, write)
+			results = append(results, processor.UpdateAction(f, actionName, opts)...)
 		}
+
+		finish(output, "update", results, check)
 	},
 }
 
@@ -71,6 +99,10 @@ func init() {
 	updateCmd.Flags().String("dir", "", "Directory containing workflow files")
 	updateCmd.Flags().String("file", "", "Specific workflow file to update")
 	updateCmd.Flags().BoolP("write", "w", false, "Write changes to files (default is dry run)")
+	updateCmd.Flags().String("strategy", "", "Update strategy: patch, minor or major (default major)")
+	updateCmd.Flags().String("min-age", "", "Only adopt releases published at least this long ago (e.g. 7d, 2w, 36h)")
+	updateCmd.Flags().Bool("allow-prerelease", false, "Adopt prereleases as well as releases")
+	updateCmd.Flags().Bool("check", false, "Exit with code 1 if any update is available, without writing changes")
 
 	// Mark action as required
 	updateCmd.MarkFlagRequired("action")
@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/behnh/actions-toolkit/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify that pinned SHAs match their version comments",
+	Long: `Verify that every SHA-pinned GitHub Action matches its version comment.
+
+Each version comment (e.g. "# v4.3.0" or "# pin@v4") is resolved back to a commit and
+compared with the pinned SHA. SHAs that no tag points at, and SHAs that are not reachable
+from the action's repository at all (for example commits from a fork), are also reported.
+With --offline, SHAs are only checked against the commits recorded by the lock command in
+.github/actions.lock, and SHAs that are not recorded there cannot be verified.
+Exits with code 1 if any problem is found, or code 2 if an action could not be verified.`,
+	Example: `  # Verify all pinned actions in a directory
+  actions-toolkit verify --dir .github/workflows
+
+  # Verify against the lockfile, without access to GitHub
+  actions-toolkit verify --offline`,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, err := getOutputFormat(cmd)
+		if err != nil {
+			slog.Error("Invalid output format", "error", err)
+			os.Exit(exitError)
+		}
+
+		opts, err := getProcessorOptions(cmd)
+		if err != nil {
+			slog.Error("Invalid configuration", "error", err)
+			os.Exit(exitError)
+		}
+
+		filesToProcess, err := getFilesToProcess(cmd)
+		if err != nil {
+			slog.Error("Failed to select files", "error", err)
+			os.Exit(exitError)
+		}
+
+		results := processor.VerifyActions(filesToProcess, opts)
+		if summary := processor.Summarize(results); summary.Problems == 0 && summary.Failed == 0 {
+			slog.Info("All pinned actions verified", "checked", summary.Total)
+		}
+
+		finish(output, "verify", results, false)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().String("dir", "", "Directory containing workflow files")
+	verifyCmd.Flags().String("file", "", "Specific workflow file to verify")
+	verifyCmd.Flags().Bool("offline", false, "Resolve actions from the lockfile only, without contacting GitHub")
+	verifyCmd.Flags().String("lockfile", "", "Path to the lockfile (default is .github/actions.lock in the repository root)")
+}
@@ -0,0 +1,224 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cache stores responses from the GitHub API on disk, so that they can be
+// reused across runs.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultTTL is how long an entry is used without revalidating it.
+const DefaultTTL = time.Hour
+
+// entriesDir is the subdirectory of the cache directory that entries are stored in,
+// so that clearing the cache never touches other files.
+const entriesDir = "http"
+
+// Entry is a cached response.
+type Entry struct {
+	URL      string      `json:"url"`
+	StoredAt time.Time   `json:"stored_at"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+}
+
+// ETag returns the entity tag of the response, used to revalidate the entry.
+func (e *Entry) ETag() string {
+	return e.Header.Get("ETag")
+}
+
+// Cache is a directory of cached responses. Entries younger than the TTL are fresh;
+// older entries are kept so that they can be revalidated.
+type Cache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time // Clock that entries are stored and aged by
+}
+
+// Stats describes the contents of a cache.
+type Stats struct {
+	Dir     string
+	Entries int
+	Expired int   // Entries older than the TTL
+	Size    int64 // Total size of the entries in bytes
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// DefaultDir returns the default cache directory: actions-toolkit in the user's cache
+// directory, e.g. $XDG_CACHE_HOME/actions-toolkit or ~/.cache/actions-toolkit on Linux.
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "actions-toolkit"), nil
+}
+
+// New returns the cache in dir, whose entries are fresh for ttl. The directory is
+// created when the first entry is stored.
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{dir: dir, ttl: ttl, now: time.Now}
+}
+
+// Dir returns the directory of the cache.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// TTL returns how long entries are fresh for.
+func (c *Cache) TTL() time.Duration {
+	return c.ttl
+}
+
+// Get returns the entry stored under key, and whether it is still fresh.
+func (c *Cache) Get(key string) (*Entry, bool) {
+	entry, err := readEntry(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return entry, c.fresh(entry)
+}
+
+// Put stores an entry under key as of now, replacing any entry already stored. Storing
+// an entry again after revalidating it makes it fresh again.
+func (c *Cache) Put(key string, entry *Entry) error {
+	entry.StoredAt = c.now()
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(c.dir, entriesDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that a concurrent Get never sees a partial entry
+	tmp, err := os.CreateTemp(dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Clear removes every entry from the cache, and returns how many were removed.
+func (c *Cache) Clear() (int, error) {
+	removed := 0
+	err := c.walk(func(path string, _ fs.FileInfo) error {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	return removed, err
+}
+
+// Stats returns statistics about the entries in the cache.
+func (c *Cache) Stats() (Stats, error) {
+	stats := Stats{Dir: c.dir}
+	err := c.walk(func(path string, info fs.FileInfo) error {
+		stats.Entries++
+		stats.Size += info.Size()
+		entry, err := readEntry(path)
+		if err != nil {
+			// Unreadable entries are replaced when they are next stored
+			return nil
+		}
+		if !c.fresh(entry) {
+			stats.Expired++
+		}
+		if stats.Oldest.IsZero() || entry.StoredAt.Before(stats.Oldest) {
+			stats.Oldest = entry.StoredAt
+		}
+		if entry.StoredAt.After(stats.Newest) {
+			stats.Newest = entry.StoredAt
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// walk calls fn for each entry file in the cache.
+func (c *Cache) walk(fn func(path string, info fs.FileInfo) error) error {
+	entries, err := os.ReadDir(filepath.Join(c.dir, entriesDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		if err := fn(filepath.Join(c.dir, entriesDir, e.Name()), info); err != nil {
+			return fmt.Errorf("failed to read cache entry %s: %w", e.Name(), err)
+		}
+	}
+	return nil
+}
+
+func readEntry(path string) (*Entry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entry Entry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (c *Cache) fresh(entry *Entry) bool {
+	return c.now().Sub(entry.StoredAt) < c.ttl
+}
+
+// path returns the file an entry is stored in. Keys are hashed, since they are URLs.
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, entriesDir, hex.EncodeToString(sum[:])+".json")
+}
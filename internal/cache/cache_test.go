@@ -0,0 +1,100 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	start := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	clock := start
+
+	dir := t.TempDir()
+	// Files that are not entries are left alone
+	other := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(other, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := New(dir, time.Hour)
+	c.now = func() time.Time { return clock }
+	if entry, _ := c.Get("https://api.github.com/repos/actions/checkout/releases"); entry != nil {
+		t.Errorf("Get() on an empty cache = %+v, want nil", entry)
+	}
+
+	header := http.Header{"Etag": []string{`"abc"`}}
+	if err := c.Put("releases", &Entry{URL: "releases", Header: header, Body: []byte("[]")}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	clock = start.Add(30 * time.Minute)
+	if err := c.Put("tags", &Entry{URL: "tags", Body: []byte("[]")}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	entry, fresh := c.Get("releases")
+	if entry == nil || !fresh {
+		t.Fatalf("Get() = %+v, %v, want a fresh entry", entry, fresh)
+	}
+	if entry.ETag() != `"abc"` || string(entry.Body) != "[]" {
+		t.Errorf("Get() = %+v, want the stored entry", entry)
+	}
+
+	clock = start.Add(time.Hour)
+	if entry, fresh := c.Get("releases"); entry == nil || fresh {
+		t.Errorf("Get() after the TTL = %+v, %v, want an expired entry", entry, fresh)
+	}
+
+	stats, err := c.Stats()
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if stats.Entries != 2 || stats.Expired != 1 || stats.Size == 0 {
+		t.Errorf("Stats() = %+v, want 2 entries with 1 expired", stats)
+	}
+	if !stats.Oldest.Equal(start) || !stats.Newest.Equal(start.Add(30*time.Minute)) {
+		t.Errorf("Stats() oldest = %v and newest = %v, want %v and %v", stats.Oldest, stats.Newest, start, start.Add(30*time.Minute))
+	}
+
+	removed, err := c.Clear()
+	if err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Clear() removed %d entries, want 2", removed)
+	}
+	if entry, _ := c.Get("tags"); entry != nil {
+		t.Errorf("Get() after Clear() = %+v, want nil", entry)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("Clear() removed a file that is not an entry: %v", err)
+	}
+}
+
+func TestStatsMissingDir(t *testing.T) {
+	stats, err := New(filepath.Join(t.TempDir(), "missing"), time.Hour).Stats()
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if stats.Entries != 0 {
+		t.Errorf("Stats() = %+v, want no entries", stats)
+	}
+}
@@ -0,0 +1,274 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// FileNames are the names a configuration file is discovered under, in order of preference.
+var FileNames = []string{".actions-toolkit.yaml", ".actions-toolkit.yml"}
+
+// defaultInclude selects the workflows of a repository when the configuration does
+// not list any include globs.
+var defaultInclude = []string{".github/workflows/*.yaml", ".github/workflows/*.yml"}
+
+// Config is the project configuration, read from .actions-toolkit.yaml.
+type Config struct {
+	Include         []string                `yaml:"include"`          // Globs of files to process, relative to the root
+	Exclude         []string                `yaml:"exclude"`          // Globs of files to leave alone, relative to the root
+	Ignore          []string                `yaml:"ignore"`           // Actions and images to leave alone
+	Actions         map[string]ActionConfig `yaml:"actions"`          // Settings for individual actions, by name
+	Strategy        string                  `yaml:"strategy"`         // Default update strategy: patch, minor or major
+	MinAge          string                  `yaml:"min-age"`          // Minimum age of a release before it is adopted (e.g., 7d)
+	AllowPrerelease bool                    `yaml:"allow-prerelease"` // Adopt prereleases as well as releases
+	CommentStyle    string                  `yaml:"comment-style"`    // Style of added version comments: version, pin or none
+	Output          string                  `yaml:"output"`           // Default output format: text, json or sarif
+	GitHubURL       string                  `yaml:"github-url"`       // GitHub instance to resolve actions against (e.g., a GHES URL)
+	GitHubOwners    map[string]string       `yaml:"github-owners"`    // GitHub instance to resolve each owner's actions against
+	CacheDir        string                  `yaml:"cache-dir"`        // Directory of the GitHub API cache, relative to the root
+	CacheTTL        string                  `yaml:"cache-ttl"`        // How long cached responses are used before revalidating them (e.g., 1h)
+	Concurrency     int                     `yaml:"concurrency"`      // Number of actions to resolve at the same time
+
+	// Path is the file the configuration was loaded from, empty if there is none
+	Path string `yaml:"-"`
+	// Root is the directory that globs are relative to
+	Root string `yaml:"-"`
+}
+
+// ActionConfig holds the settings for a single action.
+type ActionConfig struct {
+	Version   string `yaml:"version"`    // Version to pin or update to instead of the latest release
+	Strategy  string `yaml:"strategy"`   // Update strategy to use instead of the default
+	TagPrefix string `yaml:"tag-prefix"` // Prefix of the action's release tags in a monorepo (e.g., sub- for sub-v1.2.0)
+}
+
+// Load reads the configuration at path. If path is empty, the configuration is
+// discovered in the root of the repository containing the working directory, and an
+// empty configuration rooted there is returned if there is none. Outside a repository,
+// the working directory is the root.
+func Load(path string) (*Config, error) {
+	if path == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		path = Find(wd)
+		if path == "" {
+			root := repositoryRoot(wd)
+			if root == "" {
+				root = wd
+			}
+			return &Config{Root: root}, nil
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	decoder := yamlv3.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+
+	for _, pattern := range append(append([]string{}, cfg.Include...), cfg.Exclude...) {
+		if !validGlob(pattern) {
+			return nil, fmt.Errorf("invalid glob %q in configuration file %s", pattern, path)
+		}
+	}
+
+	cfg.Path = path
+	cfg.Root, err = filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Find returns the path of the configuration file in the root of the repository
+// containing dir, or in dir itself if it is not in a repository. It returns an
+// empty string if there is no configuration file.
+func Find(dir string) string {
+	root := repositoryRoot(dir)
+	if root == "" {
+		root = dir
+	}
+	for _, name := range FileNames {
+		candidate := filepath.Join(root, name)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Files returns the files under the root that match the include globs and none of
+// the exclude globs, in lexical order.
+func (c *Config) Files() ([]string, error) {
+	include := c.Include
+	if len(include) == 0 {
+		include = defaultInclude
+	}
+
+	var files []string
+	err := filepath.WalkDir(c.Root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(c.Root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if matchAny(include, rel) && !matchAny(c.Exclude, rel) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// Excluded reports whether a file matches one of the exclude globs.
+func (c *Config) Excluded(filePath string) bool {
+	if len(c.Exclude) == 0 {
+		return false
+	}
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(c.Root, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	return matchAny(c.Exclude, filepath.ToSlash(rel))
+}
+
+// Versions returns the version configured for each action that has one.
+func (c *Config) Versions() map[string]string {
+	versions := make(map[string]string)
+	for name, action := range c.Actions {
+		if action.Version != "" {
+			versions[name] = action.Version
+		}
+	}
+	return versions
+}
+
+// Strategies returns the update strategy configured for each action that has one.
+func (c *Config) Strategies() map[string]string {
+	strategies := make(map[string]string)
+	for name, action := range c.Actions {
+		if action.Strategy != "" {
+			strategies[name] = action.Strategy
+		}
+	}
+	return strategies
+}
+
+// TagPrefixes returns the tag prefix configured for each action that has one.
+func (c *Config) TagPrefixes() map[string]string {
+	prefixes := make(map[string]string)
+	for name, action := range c.Actions {
+		if action.TagPrefix != "" {
+			prefixes[name] = action.TagPrefix
+		}
+	}
+	return prefixes
+}
+
+// repositoryRoot returns the closest directory at or above dir that contains a .git
+// entry, or an empty string if there is none.
+func repositoryRoot(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(strings.Split(pattern, "/"), strings.Split(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches slash separated path segments against glob segments, where a
+// "**" segment matches any number of directories.
+func matchGlob(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlob(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], name[0]); !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+func validGlob(pattern string) bool {
+	_, err := path.Match(pattern, "")
+	return err == nil
+}
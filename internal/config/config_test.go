@@ -0,0 +1,184 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".actions-toolkit.yaml")
+	writeFile(t, path, `include:
+  - .github/workflows/*.yml
+  - "**/action.yml"
+exclude:
+  - .github/workflows/generated-*.yml
+ignore:
+  - my-org/*
+actions:
+  actions/cache:
+    version: v3
+  actions/checkout: {}
+  actions/setup-node:
+    strategy: patch
+  aws-actions/toolkit/deploy:
+    tag-prefix: deploy-
+strategy: minor
+min-age: 7d
+allow-prerelease: true
+comment-style: pin
+output: json
+github-url: https://github.example.com
+github-owners:
+  actions: https://github.com
+cache-dir: .cache/actions-toolkit
+cache-ttl: 6h
+concurrency: 4
+`)
+
+	cfg, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, path, cfg.Path)
+	assert.Equal(t, dir, cfg.Root)
+	assert.Equal(t, []string{".github/workflows/*.yml", "**/action.yml"}, cfg.Include)
+	assert.Equal(t, []string{"my-org/*"}, cfg.Ignore)
+	assert.Equal(t, map[string]string{"actions/cache": "v3"}, cfg.Versions())
+	assert.Equal(t, map[string]string{"actions/setup-node": "patch"}, cfg.Strategies())
+	assert.Equal(t, map[string]string{"aws-actions/toolkit/deploy": "deploy-"}, cfg.TagPrefixes())
+	assert.Equal(t, "minor", cfg.Strategy)
+	assert.Equal(t, "7d", cfg.MinAge)
+	assert.True(t, cfg.AllowPrerelease)
+	assert.Equal(t, "pin", cfg.CommentStyle)
+	assert.Equal(t, "json", cfg.Output)
+	assert.Equal(t, "https://github.example.com", cfg.GitHubURL)
+	assert.Equal(t, map[string]string{"actions": "https://github.com"}, cfg.GitHubOwners)
+	assert.Equal(t, ".cache/actions-toolkit", cfg.CacheDir)
+	assert.Equal(t, "6h", cfg.CacheTTL)
+	assert.Equal(t, 4, cfg.Concurrency)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	unknown := filepath.Join(dir, "unknown.yaml")
+	writeFile(t, unknown, "includes:\n  - '*.yml'\n")
+	_, err := Load(unknown)
+	assert.Error(t, err)
+
+	badGlob := filepath.Join(dir, "glob.yaml")
+	writeFile(t, badGlob, "include:\n  - '[.yml'\n")
+	_, err = Load(badGlob)
+	assert.Error(t, err)
+
+	_, err = Load(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestLoadEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".actions-toolkit.yaml")
+	writeFile(t, path, "")
+
+	cfg, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, path, cfg.Path)
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+	nested := filepath.Join(root, "a", "b")
+	assert.NoError(t, os.MkdirAll(nested, 0755))
+
+	assert.Equal(t, "", Find(nested))
+
+	writeFile(t, filepath.Join(root, ".actions-toolkit.yml"), "output: json\n")
+	assert.Equal(t, filepath.Join(root, ".actions-toolkit.yml"), Find(nested))
+
+	writeFile(t, filepath.Join(root, ".actions-toolkit.yaml"), "output: json\n")
+	assert.Equal(t, filepath.Join(root, ".actions-toolkit.yaml"), Find(nested))
+}
+
+func TestFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{
+		".github/workflows/ci.yml",
+		".github/workflows/release.yaml",
+		".github/workflows/generated-docs.yml",
+		".github/workflows/README.md",
+		"actions/setup/action.yml",
+		".git/action.yml",
+	} {
+		writeFile(t, filepath.Join(root, name), "")
+	}
+
+	cfg := &Config{Root: root}
+	files, err := cfg.Files()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, ".github/workflows/ci.yml"),
+		filepath.Join(root, ".github/workflows/generated-docs.yml"),
+		filepath.Join(root, ".github/workflows/release.yaml"),
+	}, files)
+
+	cfg = &Config{
+		Root:    root,
+		Include: []string{".github/workflows/*.yml", "**/action.yml"},
+		Exclude: []string{"**/generated-*"},
+	}
+	files, err = cfg.Files()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, ".github/workflows/ci.yml"),
+		filepath.Join(root, "actions/setup/action.yml"),
+	}, files)
+
+	assert.True(t, cfg.Excluded(filepath.Join(root, ".github/workflows/generated-docs.yml")))
+	assert.False(t, cfg.Excluded(filepath.Join(root, ".github/workflows/ci.yml")))
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*.yml", "ci.yml", true},
+		{"*.yml", "dir/ci.yml", false},
+		{"**/*.yml", "ci.yml", true},
+		{"**/*.yml", "a/b/ci.yml", true},
+		{".github/**", ".github/workflows/ci.yml", true},
+		{".github/**/ci.yml", ".github/ci.yml", true},
+		{".github/workflows/*.yml", ".github/workflows/ci.yaml", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchAny([]string{tt.pattern}, tt.name))
+		})
+	}
+}
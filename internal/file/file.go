@@ -22,8 +22,6 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-
-	yamlv3 "gopkg.in/yaml.v3"
 )
 
 // GetYAMLFiles returns a slice of paths to all YAML files (with .yml or .yaml extensions)
@@ -116,43 +114,20 @@ func ReadFile(filePath string) ([]byte, error) {
 }
 
 // ParseYAMLForUses parses a YAML file and extracts all 'uses' values from steps
-// at paths matching 'jobs.*.steps.*' or 'runs.steps.*'.
+// at paths matching 'jobs.*.steps.*' or 'runs.steps.*', and from reusable workflow
+// calls at 'jobs.*.uses'.
 func ParseYAMLForUses(content []byte) ([]string, error) {
-	var data map[string]interface{}
-	if err := yamlv3.Unmarshal(content, &data); err != nil {
+	uses, err := ParseUses("", content)
+	if err != nil {
 		return nil, err
 	}
 
 	var usesValues []string
-
-	// Check for jobs.*.steps.* path
-	if jobs, ok := data["jobs"].(map[string]interface{}); ok {
-		for _, job := range jobs {
-			if jobMap, ok := job.(map[string]interface{}); ok {
-				if steps, ok := jobMap["steps"].([]interface{}); ok {
-					for _, step := range steps {
-						if stepMap, ok := step.(map[string]interface{}); ok {
-							if uses, ok := stepMap["uses"].(string); ok {
-								usesValues = append(usesValues, uses)
-							}
-						}
-					}
-				}
-			}
-		}
-	}
-
-	// Check for runs.steps.* path
-	if runs, ok := data["runs"].(map[string]interface{}); ok {
-		if steps, ok := runs["steps"].([]interface{}); ok {
-			for _, step := range steps {
-				if stepMap, ok := step.(map[string]interface{}); ok {
-					if uses, ok := stepMap["uses"].(string); ok {
-						usesValues = append(usesValues, uses)
-					}
-				}
-			}
+	for _, u := range uses {
+		if u.Kind == UsesContainer || u.Kind == UsesService {
+			continue
 		}
+		usesValues = append(usesValues, u.Value)
 	}
 
 	return usesValues, nil
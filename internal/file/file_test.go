@@ -170,7 +170,18 @@ runs:
     - uses: actions/setup-go@v3
 `)
 
-	// Test case 4: Invalid YAML
+	// Test case 4: YAML with a reusable workflow call at jobs.*.uses
+	yamlWithReusableWorkflow := []byte(`
+jobs:
+  call:
+    uses: octo-org/ci/.github/workflows/build.yml@v2
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+`)
+
+	// Test case 5: Invalid YAML
 	invalidYAML := []byte(`
 this is not valid yaml
   - foo: bar
@@ -212,6 +223,15 @@ this is not valid yaml
 			},
 			wantErr: false,
 		},
+		{
+			name: "YAML with reusable workflow call",
+			yaml: yamlWithReusableWorkflow,
+			expected: []string{
+				"octo-org/ci/.github/workflows/build.yml@v2",
+				"actions/checkout@v4",
+			},
+			wantErr: false,
+		},
 		{
 			name:     "Invalid YAML",
 			yaml:     invalidYAML,
@@ -0,0 +1,257 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// UsesKind describes where in a file a Uses value was found.
+type UsesKind string
+
+const (
+	// UsesStep is an action referenced from a step (jobs.<id>.steps[*].uses or runs.steps[*].uses)
+	UsesStep UsesKind = "step"
+	// UsesWorkflow is a reusable workflow called from a job (jobs.<id>.uses)
+	UsesWorkflow UsesKind = "workflow"
+	// UsesContainer is the image a job runs in (jobs.<id>.container or jobs.<id>.container.image)
+	UsesContainer UsesKind = "container"
+	// UsesService is the image of a service container (jobs.<id>.services.<id>.image)
+	UsesService UsesKind = "service"
+)
+
+// IsImage reports whether the value is a container image rather than an action or
+// workflow reference. Steps can also run images directly using docker:// values.
+func (u Uses) IsImage() bool {
+	return u.Kind == UsesContainer || u.Kind == UsesService || strings.HasPrefix(u.Value, DockerPrefix)
+}
+
+// DockerPrefix marks a step that runs a container image instead of an action.
+const DockerPrefix = "docker://"
+
+// Uses is a single 'uses' value found in a workflow or action file, along with
+// its exact location in the source.
+type Uses struct {
+	File    string   // Path of the file the value was found in
+	Value   string   // The parsed value (e.g., actions/checkout@v4)
+	Line    int      // 1-based line of the value
+	Column  int      // 1-based column of the value, in characters, after any opening quote
+	Start   int      // Byte offset of the first character of the value
+	End     int      // Byte offset just past the last character of the value
+	Job     string   // ID of the owning job, empty for composite actions
+	Step    int      // Index of the owning step within its job, or -1 for job-level values
+	Kind    UsesKind // Where the value was found
+	Comment string   // Text of the trailing comment, without the leading '#'
+}
+
+// Edit replaces the bytes in [Start, End) with Text.
+type Edit struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// ParseUses parses a YAML file and returns every 'uses' value from steps at paths
+// matching 'jobs.*.steps.*' or 'runs.steps.*', from reusable workflow calls at
+// 'jobs.*.uses', and the container and service images of each job, in the order
+// they appear in the file.
+func ParseUses(filePath string, content []byte) ([]Uses, error) {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	p := usesParser{file: filePath, content: content, lineStarts: lineStarts(content)}
+	root := doc.Content[0]
+
+	// Check for jobs.*.uses, jobs.*.container, jobs.*.services and jobs.*.steps.* paths
+	if jobs := mappingValue(root, "jobs"); jobs != nil && jobs.Kind == yamlv3.MappingNode {
+		for i := 0; i+1 < len(jobs.Content); i += 2 {
+			job, jobNode := jobs.Content[i].Value, jobs.Content[i+1]
+			if node := mappingValue(jobNode, "uses"); node != nil && node.Kind == yamlv3.ScalarNode {
+				p.add(node, job, -1, UsesWorkflow)
+			}
+			p.images(job, jobNode)
+			p.steps(job, mappingValue(jobNode, "steps"))
+		}
+	}
+
+	// Check for runs.steps.* path
+	if runs := mappingValue(root, "runs"); runs != nil {
+		p.steps("", mappingValue(runs, "steps"))
+	}
+
+	return p.uses, nil
+}
+
+// ApplyEdits returns a copy of content with all edits applied. Edits may be given
+// in any order but must not overlap.
+func ApplyEdits(content []byte, edits []Edit) ([]byte, error) {
+	sorted := make([]Edit, len(edits))
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	var buf bytes.Buffer
+	last := 0
+	for _, e := range sorted {
+		if e.Start < last || e.End < e.Start || e.End > len(content) {
+			return nil, fmt.Errorf("invalid or overlapping edit at bytes %d-%d", e.Start, e.End)
+		}
+		buf.Write(content[last:e.Start])
+		buf.WriteString(e.Text)
+		last = e.End
+	}
+	buf.Write(content[last:])
+
+	return buf.Bytes(), nil
+}
+
+// LineEnd returns the byte offset of the end of the line containing offset,
+// excluding any line terminator.
+func LineEnd(content []byte, offset int) int {
+	end := len(content)
+	if i := bytes.IndexByte(content[offset:], '\n'); i >= 0 {
+		end = offset + i
+	}
+	if end > offset && content[end-1] == '\r' {
+		end--
+	}
+	return end
+}
+
+type usesParser struct {
+	file       string
+	content    []byte
+	lineStarts []int
+	uses       []Uses
+}
+
+func (p *usesParser) steps(job string, steps *yamlv3.Node) {
+	if steps == nil || steps.Kind != yamlv3.SequenceNode {
+		return
+	}
+	for i, step := range steps.Content {
+		if node := mappingValue(step, "uses"); node != nil && node.Kind == yamlv3.ScalarNode {
+			p.add(node, job, i, UsesStep)
+		}
+	}
+}
+
+// images collects the container image of a job, which may be given directly or
+// under an 'image' key, and the images of its service containers.
+func (p *usesParser) images(job string, jobNode *yamlv3.Node) {
+	if container := mappingValue(jobNode, "container"); container != nil {
+		if container.Kind != yamlv3.ScalarNode {
+			container = mappingValue(container, "image")
+		}
+		if container != nil && container.Kind == yamlv3.ScalarNode {
+			p.add(container, job, -1, UsesContainer)
+		}
+	}
+
+	services := mappingValue(jobNode, "services")
+	if services == nil || services.Kind != yamlv3.MappingNode {
+		return
+	}
+	for i := 1; i < len(services.Content); i += 2 {
+		if node := mappingValue(services.Content[i], "image"); node != nil && node.Kind == yamlv3.ScalarNode {
+			p.add(node, job, -1, UsesService)
+		}
+	}
+}
+
+func (p *usesParser) add(node *yamlv3.Node, job string, step int, kind UsesKind) {
+	column := node.Column
+	if node.Style == yamlv3.DoubleQuotedStyle || node.Style == yamlv3.SingleQuotedStyle {
+		column++
+	}
+	start, ok := p.offset(node.Line, column)
+	end := start + len(node.Value)
+
+	// Only keep values whose source text matches exactly, so that edits can never
+	// touch anything other than the value itself.
+	if !ok || end > len(p.content) || string(p.content[start:end]) != node.Value {
+		slog.Warn("Skipping uses value that cannot be located in the source",
+			"file", p.file,
+			"line", node.Line,
+			"value", node.Value)
+		return
+	}
+
+	p.uses = append(p.uses, Uses{
+		File:    p.file,
+		Value:   node.Value,
+		Line:    node.Line,
+		Column:  column,
+		Start:   start,
+		End:     end,
+		Job:     job,
+		Step:    step,
+		Kind:    kind,
+		Comment: strings.TrimSpace(strings.TrimPrefix(node.LineComment, "#")),
+	})
+}
+
+// offset converts a 1-based line and character column into a byte offset.
+func (p *usesParser) offset(line, column int) (int, bool) {
+	if line < 1 || line > len(p.lineStarts) {
+		return 0, false
+	}
+	offset := p.lineStarts[line-1]
+	for i := 1; i < column; i++ {
+		if offset >= len(p.content) || p.content[offset] == '\n' {
+			return 0, false
+		}
+		_, size := utf8.DecodeRune(p.content[offset:])
+		offset += size
+	}
+	return offset, true
+}
+
+// lineStarts returns the byte offset at which each line of content begins.
+func lineStarts(content []byte) []int {
+	starts := []int{0}
+	for i, b := range content {
+		if b == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil.
+func mappingValue(node *yamlv3.Node, key string) *yamlv3.Node {
+	if node == nil || node.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"testing"
+)
+
+func TestParseUses(t *testing.T) {
+	content := []byte(`jobs:
+  build:
+    steps:
+      # uses: actions/checkout@v4 in a comment
+      - uses: actions/checkout@v4 # v4.2.2
+      - name: ünïcode
+        run: echo "actions/checkout@v4"
+      - { name: é, uses: actions/cache@v3 }
+      - uses: "actions/setup-node@v4.3.0"
+runs:
+  steps:
+    - uses: 'actions/setup-go@v5'
+`)
+
+	got, err := ParseUses("workflow.yaml", content)
+	if err != nil {
+		t.Fatalf("ParseUses returned an error: %v", err)
+	}
+
+	expected := []struct {
+		value   string
+		line    int
+		column  int
+		job     string
+		step    int
+		comment string
+	}{
+		{"actions/checkout@v4", 5, 15, "build", 0, "v4.2.2"},
+		{"actions/cache@v3", 8, 26, "build", 2, ""},
+		{"actions/setup-node@v4.3.0", 9, 16, "build", 3, ""},
+		{"actions/setup-go@v5", 12, 14, "", 0, ""},
+	}
+
+	if len(got) != len(expected) {
+		t.Fatalf("ParseUses() got %d values, want %d", len(got), len(expected))
+	}
+
+	for i, want := range expected {
+		u := got[i]
+		if u.Value != want.value || u.Line != want.line || u.Column != want.column || u.Job != want.job || u.Step != want.step || u.Comment != want.comment {
+			t.Errorf("ParseUses()[%d] = %+v, want %+v", i, u, want)
+		}
+		if u.File != "workflow.yaml" {
+			t.Errorf("ParseUses()[%d].File = %q, want %q", i, u.File, "workflow.yaml")
+		}
+		if string(content[u.Start:u.End]) != want.value {
+			t.Errorf("ParseUses()[%d] byte range holds %q, want %q", i, content[u.Start:u.End], want.value)
+		}
+	}
+}
+
+func TestParseUsesReusableWorkflow(t *testing.T) {
+	content := []byte(`jobs:
+  call:
+    uses: octo-org/ci/.github/workflows/build.yml@v2 # v2.1.0
+  local:
+    uses: ./.github/workflows/local.yml
+`)
+
+	got, err := ParseUses("workflow.yaml", content)
+	if err != nil {
+		t.Fatalf("ParseUses returned an error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("ParseUses() got %d values, want 2", len(got))
+	}
+
+	u := got[0]
+	if u.Kind != UsesWorkflow || u.Job != "call" || u.Step != -1 || u.Comment != "v2.1.0" {
+		t.Errorf("ParseUses()[0] = %+v, want a job-level workflow call in job 'call'", u)
+	}
+	if string(content[u.Start:u.End]) != "octo-org/ci/.github/workflows/build.yml@v2" {
+		t.Errorf("ParseUses()[0] byte range holds %q", content[u.Start:u.End])
+	}
+	if got[1].Kind != UsesWorkflow || got[1].Job != "local" {
+		t.Errorf("ParseUses()[1] = %+v, want a job-level workflow call in job 'local'", got[1])
+	}
+}
+
+func TestParseUsesImages(t *testing.T) {
+	content := []byte(`jobs:
+  short:
+    container: node:18
+  long:
+    container:
+      image: ghcr.io/org/app:1.0
+    services:
+      db:
+        image: postgres:16
+    steps:
+      - uses: docker://alpine:3.19
+`)
+
+	got, err := ParseUses("workflow.yaml", content)
+	if err != nil {
+		t.Fatalf("ParseUses returned an error: %v", err)
+	}
+
+	expected := []struct {
+		value string
+		kind  UsesKind
+		job   string
+	}{
+		{"node:18", UsesContainer, "short"},
+		{"ghcr.io/org/app:1.0", UsesContainer, "long"},
+		{"postgres:16", UsesService, "long"},
+		{"docker://alpine:3.19", UsesStep, "long"},
+	}
+
+	if len(got) != len(expected) {
+		t.Fatalf("ParseUses() got %d values, want %d", len(got), len(expected))
+	}
+	for i, want := range expected {
+		if got[i].Value != want.value || got[i].Kind != want.kind || got[i].Job != want.job {
+			t.Errorf("ParseUses()[%d] = %+v, want %+v", i, got[i], want)
+		}
+		if !got[i].IsImage() {
+			t.Errorf("ParseUses()[%d].IsImage() = false, want true", i)
+		}
+	}
+
+	// Images are not returned as uses values
+	values, err := ParseYAMLForUses(content)
+	if err != nil {
+		t.Fatalf("ParseYAMLForUses returned an error: %v", err)
+	}
+	if len(values) != 1 || values[0] != "docker://alpine:3.19" {
+		t.Errorf("ParseYAMLForUses() = %v, want [docker://alpine:3.19]", values)
+	}
+}
+
+func TestParseUsesEmptyDocument(t *testing.T) {
+	got, err := ParseUses("empty.yaml", []byte(""))
+	if err != nil {
+		t.Fatalf("ParseUses returned an error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("ParseUses() got %d values, want 0", len(got))
+	}
+}
+
+func TestApplyEdits(t *testing.T) {
+	content := []byte("uses: a@v1 # v1\n")
+
+	got, err := ApplyEdits(content, []Edit{
+		{Start: 10, End: 15, Text: " # v2"},
+		{Start: 6, End: 10, Text: "a@v2"},
+	})
+	if err != nil {
+		t.Fatalf("ApplyEdits returned an error: %v", err)
+	}
+	if string(got) != "uses: a@v2 # v2\n" {
+		t.Errorf("ApplyEdits() = %q, want %q", got, "uses: a@v2 # v2\n")
+	}
+
+	// Overlapping edits are rejected
+	_, err = ApplyEdits(content, []Edit{{Start: 6, End: 10, Text: "x"}, {Start: 8, End: 12, Text: "y"}})
+	if err == nil {
+		t.Error("Expected error for overlapping edits, but got nil")
+	}
+}
+
+func TestLineEnd(t *testing.T) {
+	content := []byte("one\r\ntwo\nthree")
+
+	tests := []struct {
+		offset int
+		want   int
+	}{
+		{0, 3},
+		{5, 8},
+		{9, 14},
+	}
+
+	for _, tt := range tests {
+		if got := LineEnd(content, tt.offset); got != tt.want {
+			t.Errorf("LineEnd(%d) = %d, want %d", tt.offset, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fixtures provides a resolver that answers from a fixture file instead of
+// GitHub and container registries, so that the processor and the CLI can be tested
+// without network access.
+package fixtures
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/behnh/actions-toolkit/internal/github"
+	"github.com/behnh/actions-toolkit/internal/processor"
+	"github.com/behnh/actions-toolkit/internal/semver"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Resolver resolves actions and images from fixtures. It implements processor.Resolver.
+//
+// A fixture file lists the releases, tags and commits of each repository, and the
+// digest of each image:
+//
+//	actions:
+//	  actions/checkout:
+//	    releases:            # Newest first; the first one that is not a prerelease is the latest release
+//	      - tag: v5.0.0-beta.1
+//	        published: 2025-01-07T09:00:00Z
+//	        prerelease: true
+//	      - tag: v4.2.2
+//	        published: 2024-10-23T16:26:38Z
+//	    tags:
+//	      v4.2.2: 11bd71901bbe5b1630ceea73d27597364c9af683
+//	      v4: 11bd71901bbe5b1630ceea73d27597364c9af683
+//	    branches:
+//	      main: 85e6279cec87321a52edac9c87bce653a07cf6c2
+//	    commits:             # Untagged commits that are reachable from the repository
+//	      - 6ccd57f4c5d15bdc2fef309bd9fb6cc9db2ef1c6
+//	images:
+//	  node:20: sha256:...
+//
+// Looking up an action or image that is not in the fixtures is an error.
+type Resolver struct {
+	Actions map[string]Repository `yaml:"actions"` // Repositories by owner/repo
+	Images  map[string]string     `yaml:"images"`  // Digests by image reference
+}
+
+var _ processor.Resolver = (*Resolver)(nil)
+
+// Repository holds the fixtures of a repository.
+type Repository struct {
+	Releases []Release         `yaml:"releases"`
+	Tags     map[string]string `yaml:"tags"`     // Commit SHAs by tag name
+	Branches map[string]string `yaml:"branches"` // Commit SHAs by branch name
+	Commits  []string          `yaml:"commits"`  // Untagged commits in the repository
+}
+
+// Release is a published release of a repository.
+type Release struct {
+	Tag        string    `yaml:"tag"`
+	Published  time.Time `yaml:"published"`
+	Prerelease bool      `yaml:"prerelease"`
+}
+
+// Load reads a fixture file.
+func Load(path string) (*Resolver, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fixture file %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Parse parses the content of a fixture file.
+func Parse(content []byte) (*Resolver, error) {
+	r := &Resolver{}
+	decoder := yamlv3.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(r); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return r, nil
+}
+
+// LatestRelease returns the first release of the action's repository that is not a
+// prerelease, or its highest version tag if it has no releases, like GitHub does. With
+// a tag prefix, only releases and tags that start with it are considered.
+func (r *Resolver) LatestRelease(actionName string, currentVersion string, tagPrefix string) (string, string, error) {
+	repo, err := r.repository(actionName)
+	if err != nil {
+		return "", "", err
+	}
+	released := false
+	for _, release := range repo.Releases {
+		if !strings.HasPrefix(release.Tag, tagPrefix) {
+			continue
+		}
+		released = true
+		if !release.Prerelease {
+			return release.Tag, repo.Tags[release.Tag], nil
+		}
+	}
+	if released {
+		return "", "", nil
+	}
+
+	latest := ""
+	var latestVersion semver.Version
+	for tag := range repo.Tags {
+		name, found := strings.CutPrefix(tag, tagPrefix)
+		if !found {
+			continue
+		}
+		version, ok := semver.Parse(name)
+		if !ok || version.IsPrerelease() {
+			continue
+		}
+		cmp := semver.Compare(version, latestVersion)
+		if latest == "" || cmp > 0 || (cmp == 0 && version.Parts > latestVersion.Parts) {
+			latest, latestVersion = tag, version
+		}
+	}
+	return latest, repo.Tags[latest], nil
+}
+
+// TagSHA returns the commit SHA of a tag, or an empty SHA if there is no such tag.
+func (r *Resolver) TagSHA(actionName string, tag string) (string, error) {
+	repo, err := r.repository(actionName)
+	if err != nil {
+		return "", err
+	}
+	return repo.Tags[tag], nil
+}
+
+// RefSHA returns the commit SHA of a tag or, failing that, a branch, or an empty SHA if
+// there is neither.
+func (r *Resolver) RefSHA(actionName string, ref string) (string, error) {
+	repo, err := r.repository(actionName)
+	if err != nil {
+		return "", err
+	}
+	if sha, found := repo.Tags[ref]; found {
+		return sha, nil
+	}
+	return repo.Branches[ref], nil
+}
+
+// Releases returns the releases of the action's repository.
+func (r *Resolver) Releases(actionName string) ([]github.Release, error) {
+	repo, err := r.repository(actionName)
+	if err != nil {
+		return nil, err
+	}
+	releases := make([]github.Release, len(repo.Releases))
+	for i, release := range repo.Releases {
+		releases[i] = github.Release{Tag: release.Tag, PublishedAt: release.Published, Prerelease: release.Prerelease}
+	}
+	return releases, nil
+}
+
+// TagsForCommit returns the tags that point at a commit SHA, in lexical order.
+func (r *Resolver) TagsForCommit(actionName string, sha string) ([]string, error) {
+	repo, err := r.repository(actionName)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for tag, tagSHA := range repo.Tags {
+		if tagSHA == sha {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// IsCommitInRepository reports whether a commit SHA is tagged, the head of a branch, or
+// listed as a commit of the action's repository.
+func (r *Resolver) IsCommitInRepository(actionName string, sha string) (bool, error) {
+	repo, err := r.repository(actionName)
+	if err != nil {
+		return false, err
+	}
+	for _, tagSHA := range repo.Tags {
+		if tagSHA == sha {
+			return true, nil
+		}
+	}
+	for _, branchSHA := range repo.Branches {
+		if branchSHA == sha {
+			return true, nil
+		}
+	}
+	for _, commit := range repo.Commits {
+		if commit == sha {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ImageDigest returns the digest of an image.
+func (r *Resolver) ImageDigest(image string) (string, error) {
+	digest, found := r.Images[image]
+	if !found {
+		return "", fmt.Errorf("no fixture for image %s", image)
+	}
+	return digest, nil
+}
+
+// repository returns the fixtures of the repository an action is in.
+func (r *Resolver) repository(actionName string) (Repository, error) {
+	parts := strings.SplitN(actionName, "/", 3)
+	if len(parts) < 2 {
+		return Repository{}, fmt.Errorf("invalid action name %q", actionName)
+	}
+	repo, found := r.Actions[parts[0]+"/"+parts[1]]
+	if !found {
+		return Repository{}, fmt.Errorf("no fixture for action %s", parts[0]+"/"+parts[1])
+	}
+	return repo, nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fixtures
+
+import (
+	"reflect"
+	"testing"
+)
+
+const fixture = `
+actions:
+  actions/checkout:
+    releases:
+      - tag: v5.0.0-beta.1
+        prerelease: true
+      - tag: v4.2.2
+        published: 2024-10-23T16:26:38Z
+      - tag: v4.2.1
+    tags:
+      v5.0.0-beta.1: ffffffffffffffffffffffffffffffffffffffff
+      v4.2.2: aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa
+      v4: aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa
+      v4.2.1: bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb
+    branches:
+      main: eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee
+    commits:
+      - cccccccccccccccccccccccccccccccccccccccc
+  octo-org/tags-only:
+    tags:
+      v1.9.0: 1111111111111111111111111111111111111111
+      v1.10.0: 2222222222222222222222222222222222222222
+      v2.0.0-rc.1: 4444444444444444444444444444444444444444
+      latest: 3333333333333333333333333333333333333333
+  octo-org/monorepo:
+    releases:
+      - tag: other-v2.0.0
+      - tag: sub-v1.1.0
+    tags:
+      other-v2.0.0: 5555555555555555555555555555555555555555
+      sub-v1.1.0: 6666666666666666666666666666666666666666
+images:
+  node:20: sha256:dddd
+`
+
+func TestResolver(t *testing.T) {
+	r, err := Parse([]byte(fixture))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	version, sha, err := r.LatestRelease("actions/checkout/sub", "v4", "")
+	if err != nil || version != "v4.2.2" || sha != "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" {
+		t.Errorf("LatestRelease() = %q, %q, %v", version, sha, err)
+	}
+
+	// Repositories without releases fall back to their highest version tag
+	version, sha, err = r.LatestRelease("octo-org/tags-only", "v1", "")
+	if err != nil || version != "v1.10.0" || sha != "2222222222222222222222222222222222222222" {
+		t.Errorf("LatestRelease() without releases = %q, %q, %v", version, sha, err)
+	}
+
+	// With a tag prefix, the latest release is the latest one with the prefix
+	version, sha, err = r.LatestRelease("octo-org/monorepo/sub", "sub-v1", "sub-")
+	if err != nil || version != "sub-v1.1.0" || sha != "6666666666666666666666666666666666666666" {
+		t.Errorf("LatestRelease() with a tag prefix = %q, %q, %v", version, sha, err)
+	}
+
+	if sha, err := r.TagSHA("actions/checkout", "v4.2.1"); err != nil || sha != "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb" {
+		t.Errorf("TagSHA() = %q, %v", sha, err)
+	}
+	if sha, err := r.TagSHA("actions/checkout", "v1"); err != nil || sha != "" {
+		t.Errorf("TagSHA() of a missing tag = %q, %v, want no SHA", sha, err)
+	}
+
+	for ref, want := range map[string]string{
+		"v4":      "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		"main":    "eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee",
+		"develop": "",
+	} {
+		if sha, err := r.RefSHA("actions/checkout", ref); err != nil || sha != want {
+			t.Errorf("RefSHA(%s) = %q, %v, want %q", ref, sha, err, want)
+		}
+	}
+
+	releases, err := r.Releases("actions/checkout")
+	if err != nil || len(releases) != 3 || !releases[0].Prerelease || releases[1].Tag != "v4.2.2" || releases[1].PublishedAt.IsZero() {
+		t.Errorf("Releases() = %+v, %v", releases, err)
+	}
+
+	if tags, err := r.TagsForCommit("actions/checkout", "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"); err != nil || !reflect.DeepEqual(tags, []string{"v4", "v4.2.2"}) {
+		t.Errorf("TagsForCommit() = %v, %v", tags, err)
+	}
+
+	for sha, want := range map[string]bool{
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa": true,
+		"cccccccccccccccccccccccccccccccccccccccc": true,
+		"eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee": true,
+		"9999999999999999999999999999999999999999": false,
+	} {
+		if got, err := r.IsCommitInRepository("actions/checkout", sha); err != nil || got != want {
+			t.Errorf("IsCommitInRepository(%s) = %v, %v, want %v", sha, got, err, want)
+		}
+	}
+
+	if digest, err := r.ImageDigest("node:20"); err != nil || digest != "sha256:dddd" {
+		t.Errorf("ImageDigest() = %q, %v", digest, err)
+	}
+
+	// Anything without a fixture is an error, rather than a silent miss
+	if _, _, err := r.LatestRelease("actions/cache", "v4", ""); err == nil {
+		t.Error("LatestRelease() of an action without fixtures succeeded, want an error")
+	}
+	if _, err := r.ImageDigest("alpine:3.19"); err == nil {
+		t.Error("ImageDigest() of an image without fixtures succeeded, want an error")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse([]byte("repositories: {}\n")); err == nil {
+		t.Error("Parse() with an unknown field succeeded, want an error")
+	}
+}
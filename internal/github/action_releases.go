@@ -18,25 +18,43 @@ package github
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/behnh/actions-toolkit/internal/semver"
 	"github.com/google/go-github/v72/github"
 )
 
 type ReleaseInfo struct {
 	MajorVersion string // The major version (e.g., v3)
 	FullVersion  string // The full version (e.g., v3.5.0)
-	SHA          string // The SHA of the release
+	SHA          string // The SHA of the commit the release points at
+	TagSHA       string // The SHA of the annotated tag object, empty for lightweight tags
 }
 
+// Release is a published release of an action.
+type Release struct {
+	Tag         string    // The tag the release was made from (e.g., v3.5.0)
+	PublishedAt time.Time // When the release was published
+	Prerelease  bool      // Whether the release is marked as a prerelease on GitHub
+}
+
+// maxTagDepth limits how many levels of tags pointing at tags are followed.
+const maxTagDepth = 10
+
+// releaseCache holds the latest release of each repository by releaseCacheKey
 var releaseCache = make(map[string]ReleaseInfo)
+var releaseListCache = make(map[string][]Release)
 var cacheMutex sync.RWMutex
 
-// GetLatestRelease searches for the latest release of a GitHub action.
+// GetLatestRelease searches for the latest release of a GitHub action, or for its highest
+// version tag if it does not publish releases.
 // The actionName should be in the format "org/repo/optional_subpath".
 func GetLatestRelease(token string, actionName string, currentVersion string) (string, error) {
 	// Check if we have this action in the cache
@@ -66,12 +84,13 @@ func GetLatestRelease(token string, actionName string, currentVersion string) (s
 	cacheMutex.RUnlock()
 
 	// Create a GitHub client with the provided token
-	client := github.NewClient(nil).WithAuthToken(token)
+	client := newClient(token, actionName)
 	version, _, err := getLatestReleaseWithClient(client, actionName)
 	return version, err
 }
 
 // GetLatestReleaseWithSHA searches for the latest release of a GitHub action and returns both the version and SHA.
+// Like GetLatestRelease, it falls back to the highest version tag if there are no releases.
 // The actionName should be in the format "org/repo/optional_subpath".
 func GetLatestReleaseWithSHA(token string, actionName string, currentVersion string) (string, string, error) {
 	baseActionName := getBaseActionName(actionName)
@@ -79,19 +98,8 @@ func GetLatestReleaseWithSHA(token string, actionName string, currentVersion str
 	if info, found := releaseCache[baseActionName]; found {
 		cacheMutex.RUnlock()
 
-		// If the currentVersion is a major version constraint (e.g., v4)
-		// and it matches the major version in the cache, return the current version
-		if isMajorVersionConstraint(currentVersion) {
-			currentMajorVersion := extractMajorVersion(currentVersion)
-			if currentMajorVersion == info.MajorVersion {
-				slog.Debug("Using current version (major version constraint matches)",
-					"action", actionName,
-					"currentVersion", currentVersion,
-					"latestMajorVersion", info.MajorVersion)
-				return currentVersion, "", nil
-			}
-		}
-
+		// The SHA is always returned, even if the current version is a major version
+		// constraint that matches the latest release, since callers pin to it
 		slog.Debug("Using cached release info",
 			"action", actionName,
 			"version", info.FullVersion,
@@ -100,7 +108,7 @@ func GetLatestReleaseWithSHA(token string, actionName string, currentVersion str
 	}
 	cacheMutex.RUnlock()
 
-	client := github.NewClient(nil).WithAuthToken(token)
+	client := newClient(token, actionName)
 	version, sha, err := getLatestReleaseWithClient(client, actionName)
 	if err != nil {
 		return "", "", err
@@ -136,7 +144,70 @@ func GetLatestReleaseWithSHA(token string, actionName string, currentVersion str
 	return version, "", nil
 }
 
-// getBaseActionName extracts the base action name (org/repo) from the full action name
+// GetLatestPrefixedReleaseWithSHA returns the latest release of an action in a monorepo
+// whose release tags start with prefix (e.g., sub- for sub-v1.2.0), and the commit it
+// points at. The latest release of the repository may belong to another of its actions,
+// so this is the newest release with the prefix that is not a prerelease, or the highest
+// version tag with the prefix if there is no such release. Each prefix is cached on its
+// own. It returns an empty version if nothing has the prefix.
+// The actionName should be in the format "org/repo/optional_subpath".
+func GetLatestPrefixedReleaseWithSHA(token string, actionName string, prefix string) (string, string, error) {
+	key := releaseCacheKey(actionName, prefix)
+	cacheMutex.RLock()
+	if info, found := releaseCache[key]; found {
+		cacheMutex.RUnlock()
+		slog.Debug("Using cached release info",
+			"action", actionName,
+			"prefix", prefix,
+			"version", info.FullVersion,
+			"sha", info.SHA)
+		return info.FullVersion, info.SHA, nil
+	}
+	cacheMutex.RUnlock()
+
+	return getLatestPrefixedReleaseWithClient(newClient(token, actionName), actionName, prefix)
+}
+
+// ListReleases returns all published releases of a GitHub action, newest first,
+// leaving out drafts. Prereleases are included and marked as such.
+// The actionName should be in the format "org/repo/optional_subpath".
+func ListReleases(token string, actionName string) ([]Release, error) {
+	baseActionName := getBaseActionName(actionName)
+	cacheMutex.RLock()
+	if releases, found := releaseListCache[baseActionName]; found {
+		cacheMutex.RUnlock()
+		slog.Debug("Using cached release list", "action", actionName, "releases", len(releases))
+		return releases, nil
+	}
+	cacheMutex.RUnlock()
+
+	return listReleasesWithClient(newClient(token, actionName), actionName)
+}
+
+// splitActionName splits an action name in the format "org/repo/optional_subpath" into
+// the owner and repository. ok is false if the name has no repository.
+func splitActionName(actionName string) (owner string, repo string, ok bool) {
+	parts := strings.SplitN(actionName, "/", 3)
+	if len(parts) < 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// releaseCacheKey returns the key of the latest release of an action in releaseCache:
+// its owner/repo, or for actions in a monorepo with a tag prefix, its owner/repo and
+// the prefix, since each prefix has a latest release of its own.
+func releaseCacheKey(actionName string, prefix string) string {
+	base := getBaseActionName(actionName)
+	if prefix == "" {
+		return base
+	}
+	return base + "@" + prefix
+}
+
+// getBaseActionName extracts the base action name (org/repo) from the full action name,
+// dropping any subpath such as "save" in actions/cache/save or the workflow path of a
+// reusable workflow such as org/repo/.github/workflows/build.yml
 func getBaseActionName(actionName string) string {
 	parts := strings.SplitN(actionName, "/", 3)
 	if len(parts) < 2 {
@@ -146,7 +217,7 @@ func getBaseActionName(actionName string) string {
 }
 
 // isMajorVersionConstraint checks if a version string is a major version constraint,
-// For example, "v4" or "4" are major version constraints, but "v4.3.0" is not
+// For example, "v4" or "4" are major version constraints, but "v4.3.0" and "v5-beta" are not
 func isMajorVersionConstraint(version string) bool {
 	if version == "" {
 		return false
@@ -161,6 +232,10 @@ func isMajorVersionConstraint(version string) bool {
 		}
 	}
 
+	if v, ok := semver.Parse(version); ok {
+		return v.Parts == 1 && !v.IsPrerelease()
+	}
+
 	versionStr := version
 	if strings.HasPrefix(versionStr, "v") {
 		versionStr = versionStr[1:]
@@ -169,11 +244,15 @@ func isMajorVersionConstraint(version string) bool {
 	return !strings.Contains(versionStr, ".")
 }
 
-// extractMajorVersion extracts the major version from a full version string
+// extractMajorVersion extracts the major version from a full version string,
+// ignoring any prerelease or build suffix (e.g., v5 from v5.0.0-beta.1)
 func extractMajorVersion(version string) string {
 	if version == "" {
 		return ""
 	}
+	if v, ok := semver.Parse(version); ok {
+		return v.MajorTag()
+	}
 
 	// Remove the 'v' prefix if it exists
 	versionStr := version
@@ -199,13 +278,11 @@ func extractMajorVersion(version string) string {
 // of the GitHub client for testing purposes.
 func getLatestReleaseWithClient(client *github.Client, actionName string) (string, string, error) {
 	// Parse the action name to extract org and repo
-	parts := strings.SplitN(actionName, "/", 3)
-	if len(parts) < 2 {
+	owner, repo, ok := splitActionName(actionName)
+	if !ok {
 		return "", "", nil
 	}
 
-	owner := parts[0]
-	repo := parts[1]
 	baseActionName := owner + "/" + repo
 
 	// Get the latest release
@@ -214,21 +291,21 @@ func getLatestReleaseWithClient(client *github.Client, actionName string) (strin
 	if err != nil {
 		// Check if the error is due to no releases found (404)
 		if resp != nil && resp.StatusCode == http.StatusNotFound {
-			slog.Debug("No release found for GitHub action",
+			slog.Debug("No release found for GitHub action, falling back to tags",
 				"action", actionName,
 				"owner", owner,
 				"repo", repo)
-			return "", "", nil
+			return getLatestTagWithClient(ctx, client, owner, repo, "")
 		}
 		return "", "", err
 	}
 
 	if release == nil || release.TagName == nil {
-		slog.Debug("No release found for GitHub action",
+		slog.Debug("No release found for GitHub action, falling back to tags",
 			"action", actionName,
 			"owner", owner,
 			"repo", repo)
-		return "", "", nil
+		return getLatestTagWithClient(ctx, client, owner, repo, "")
 	}
 
 	fullVersion := release.GetTagName()
@@ -243,11 +320,12 @@ func getLatestReleaseWithClient(client *github.Client, actionName string) (strin
 		return fullVersion, "", err
 	}
 
-	// Get SHA if available
-	sha := ""
-	if refResp.GetObject().GetSHA() != "" {
-		sha = refResp.GetObject().GetSHA()
-	} else {
+	// Get SHA if available, peeling annotated tags down to the commit they point at
+	sha, tagSHA, err := peelTag(ctx, client, owner, repo, refResp.GetObject())
+	if err != nil {
+		return fullVersion, "", err
+	}
+	if sha == "" {
 		sha = release.GetTargetCommitish()
 	}
 
@@ -257,6 +335,7 @@ func getLatestReleaseWithClient(client *github.Client, actionName string) (strin
 		MajorVersion: majorVersion,
 		FullVersion:  fullVersion,
 		SHA:          sha,
+		TagSHA:       tagSHA,
 	}
 	cacheMutex.Unlock()
 
@@ -264,7 +343,190 @@ func getLatestReleaseWithClient(client *github.Client, actionName string) (strin
 		"action", baseActionName,
 		"majorVersion", majorVersion,
 		"fullVersion", fullVersion,
+		"sha", sha,
+		"tagSHA", tagSHA)
+
+	return fullVersion, sha, nil
+}
+
+// getLatestPrefixedReleaseWithClient returns the newest release of a repository whose
+// tag starts with prefix and that is not a prerelease, and the commit it points at. If
+// no release has the prefix, it falls back to the highest version tag with the prefix.
+func getLatestPrefixedReleaseWithClient(client *github.Client, actionName string, prefix string) (string, string, error) {
+	owner, repo, ok := splitActionName(actionName)
+	if !ok {
+		return "", "", nil
+	}
+
+	cacheMutex.RLock()
+	releases, found := releaseListCache[owner+"/"+repo]
+	cacheMutex.RUnlock()
+	if !found {
+		var err error
+		if releases, err = listReleasesWithClient(client, actionName); err != nil {
+			return "", "", err
+		}
+	}
+
+	fullVersion := ""
+	for _, release := range releases {
+		if strings.HasPrefix(release.Tag, prefix) && !release.Prerelease {
+			fullVersion = release.Tag
+			break
+		}
+	}
+	if fullVersion == "" {
+		slog.Debug("No release found with tag prefix, falling back to tags",
+			"action", actionName,
+			"prefix", prefix)
+		return getLatestTagWithClient(context.Background(), client, owner, repo, prefix)
+	}
+
+	sha, err := getTagSHAWithClient(client, actionName, fullVersion)
+	if err != nil {
+		return fullVersion, "", err
+	}
+
+	key := releaseCacheKey(actionName, prefix)
+	cacheMutex.Lock()
+	releaseCache[key] = ReleaseInfo{
+		MajorVersion: prefix + extractMajorVersion(strings.TrimPrefix(fullVersion, prefix)),
+		FullVersion:  fullVersion,
+		SHA:          sha,
+	}
+	cacheMutex.Unlock()
+
+	slog.Debug("Cached release info",
+		"action", key,
+		"fullVersion", fullVersion,
 		"sha", sha)
 
 	return fullVersion, sha, nil
 }
+
+// getLatestTagWithClient returns the highest semver tag of a repository that does not
+// publish GitHub Releases, and the commit it points at. Only tags that start with
+// prefix are considered, and the prefix is not part of the version they are compared
+// by. Tags that are not versions and prerelease tags are left out, just as the latest
+// release is never a prerelease. When a major version tag and a full version tag are
+// equal (e.g., v4 and v4.0.0), the more specific one is used, so that it ends up in the
+// version comment.
+func getLatestTagWithClient(ctx context.Context, client *github.Client, owner, repo string, prefix string) (string, string, error) {
+	tags, err := listTags(ctx, client, owner, repo)
+	if err != nil {
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response.StatusCode == http.StatusNotFound {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+
+	var latest *github.RepositoryTag
+	var latestVersion semver.Version
+	for _, tag := range tags {
+		name, found := strings.CutPrefix(tag.GetName(), prefix)
+		if !found {
+			continue
+		}
+		version, ok := semver.Parse(name)
+		if !ok || version.IsPrerelease() {
+			continue
+		}
+		cmp := semver.Compare(version, latestVersion)
+		if latest == nil || cmp > 0 || (cmp == 0 && version.Parts > latestVersion.Parts) {
+			latest, latestVersion = tag, version
+		}
+	}
+
+	if latest == nil {
+		slog.Debug("No version tag found for GitHub action", "owner", owner, "repo", repo)
+		return "", "", nil
+	}
+
+	// The tags API returns the commit an annotated tag points at, so there is nothing to peel
+	fullVersion := latest.GetName()
+	sha := latest.GetCommit().GetSHA()
+	key := releaseCacheKey(owner+"/"+repo, prefix)
+
+	cacheMutex.Lock()
+	releaseCache[key] = ReleaseInfo{
+		MajorVersion: prefix + extractMajorVersion(strings.TrimPrefix(fullVersion, prefix)),
+		FullVersion:  fullVersion,
+		SHA:          sha,
+	}
+	cacheMutex.Unlock()
+
+	slog.Debug("Cached latest tag as release info",
+		"action", key,
+		"fullVersion", fullVersion,
+		"sha", sha)
+
+	return fullVersion, sha, nil
+}
+
+// peelTag follows a git object through any annotated tags until it reaches a commit.
+// It returns the commit SHA and, for annotated tags, the SHA of the outermost tag object.
+func peelTag(ctx context.Context, client *github.Client, owner, repo string, object *github.GitObject) (string, string, error) {
+	tagSHA := ""
+	for depth := 0; object.GetType() == "tag"; depth++ {
+		if depth == maxTagDepth {
+			return "", "", fmt.Errorf("tag %s in %s/%s is nested too deeply", tagSHA, owner, repo)
+		}
+		if tagSHA == "" {
+			tagSHA = object.GetSHA()
+		}
+
+		tag, _, err := client.Git.GetTag(ctx, owner, repo, object.GetSHA())
+		if err != nil {
+			return "", "", err
+		}
+
+		slog.Debug("Peeled annotated tag",
+			"owner", owner,
+			"repo", repo,
+			"tag", tag.GetTag(),
+			"tagSHA", object.GetSHA(),
+			"target", tag.GetObject().GetSHA())
+		object = tag.GetObject()
+	}
+
+	return object.GetSHA(), tagSHA, nil
+}
+
+func listReleasesWithClient(client *github.Client, actionName string) ([]Release, error) {
+	owner, repo, ok := splitActionName(actionName)
+	if !ok {
+		return nil, fmt.Errorf("invalid action name %q", actionName)
+	}
+
+	ctx := context.Background()
+	opts := &github.ListOptions{PerPage: 100}
+	var list []Release
+	for {
+		releases, resp, err := client.Repositories.ListReleases(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, release := range releases {
+			if release.GetDraft() {
+				continue
+			}
+			list = append(list, Release{
+				Tag:         release.GetTagName(),
+				PublishedAt: release.GetPublishedAt().Time,
+				Prerelease:  release.GetPrerelease(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	cacheMutex.Lock()
+	releaseListCache[owner+"/"+repo] = list
+	cacheMutex.Unlock()
+
+	slog.Debug("Cached release list", "action", owner+"/"+repo, "releases", len(list))
+	return list, nil
+}
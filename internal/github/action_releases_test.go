@@ -20,7 +20,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v72/github"
 )
@@ -98,11 +100,17 @@ func TestGetLatestRelease(t *testing.T) {
 			want:       "v3.5.0",
 			wantErr:    false,
 		},
+		{
+			name:       "Reusable workflow",
+			actionName: "actions/checkout/.github/workflows/build.yml",
+			want:       "v3.5.0",
+			wantErr:    false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := getLatestReleaseWithClient(mockClient, tt.actionName)
+			got, _, err := getLatestReleaseWithClient(mockClient, tt.actionName)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getLatestReleaseWithClient() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -153,7 +161,7 @@ func TestCaching(t *testing.T) {
 	mockClient.UploadURL = mockURL
 
 	// First call should hit the API
-	version1, err := getLatestReleaseWithClient(mockClient, "actions/checkout")
+	version1, _, err := getLatestReleaseWithClient(mockClient, "actions/checkout")
 	if err != nil {
 		t.Errorf("getLatestReleaseWithClient() error = %v", err)
 		return
@@ -184,7 +192,7 @@ func TestCaching(t *testing.T) {
 	}
 
 	// Second call should use the cache
-	version2, err := getLatestReleaseWithClient(mockClient, "actions/checkout")
+	version2, _, err := getLatestReleaseWithClient(mockClient, "actions/checkout")
 	if err != nil {
 		t.Errorf("getLatestReleaseWithClient() error = %v", err)
 		return
@@ -198,7 +206,7 @@ func TestCaching(t *testing.T) {
 	}
 
 	// Call with a subpath should also use the cache
-	version3, err := getLatestReleaseWithClient(mockClient, "actions/checkout/v3")
+	version3, _, err := getLatestReleaseWithClient(mockClient, "actions/checkout/v3")
 	if err != nil {
 		t.Errorf("getLatestReleaseWithClient() error = %v", err)
 		return
@@ -212,6 +220,221 @@ func TestCaching(t *testing.T) {
 	}
 }
 
+func TestGetBaseActionName(t *testing.T) {
+	tests := []struct {
+		name       string
+		actionName string
+		want       string
+	}{
+		{
+			name:       "Action",
+			actionName: "actions/checkout",
+			want:       "actions/checkout",
+		},
+		{
+			name:       "Action with subpath",
+			actionName: "actions/cache/save",
+			want:       "actions/cache",
+		},
+		{
+			name:       "Reusable workflow",
+			actionName: "octo-org/ci/.github/workflows/build.yml",
+			want:       "octo-org/ci",
+		},
+		{
+			name:       "Invalid action name format",
+			actionName: "invalid-format",
+			want:       "invalid-format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getBaseActionName(tt.actionName)
+			if got != tt.want {
+				t.Errorf("getBaseActionName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotatedTag(t *testing.T) {
+	// Clear the cache before testing
+	cacheMutex.Lock()
+	releaseCache = make(map[string]ReleaseInfo)
+	cacheMutex.Unlock()
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/actions/annotated/releases/latest":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"tag_name": "v2.0.0", "target_commitish": "main"}`))
+		case "/repos/actions/annotated/git/ref/tags/v2.0.0":
+			// Annotated tags point at a tag object rather than a commit
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{
+				"ref": "refs/tags/v2.0.0",
+				"object": {"sha": "1111111111111111111111111111111111111111", "type": "tag"}
+			}`))
+		case "/repos/actions/annotated/git/tags/1111111111111111111111111111111111111111":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{
+				"tag": "v2.0.0",
+				"sha": "1111111111111111111111111111111111111111",
+				"object": {"sha": "2222222222222222222222222222222222222222", "type": "commit"}
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	mockURL, _ := url.Parse(mockServer.URL + "/")
+	mockClient := github.NewClient(nil)
+	mockClient.BaseURL = mockURL
+
+	version, sha, err := getLatestReleaseWithClient(mockClient, "actions/annotated")
+	if err != nil {
+		t.Fatalf("getLatestReleaseWithClient() error = %v", err)
+	}
+	if version != "v2.0.0" {
+		t.Errorf("getLatestReleaseWithClient() version = %v, want %v", version, "v2.0.0")
+	}
+	if sha != "2222222222222222222222222222222222222222" {
+		t.Errorf("getLatestReleaseWithClient() sha = %v, want the peeled commit SHA", sha)
+	}
+
+	cacheMutex.RLock()
+	info := releaseCache["actions/annotated"]
+	cacheMutex.RUnlock()
+	if info.SHA != "2222222222222222222222222222222222222222" {
+		t.Errorf("Cache has wrong SHA: got %v, want the commit SHA", info.SHA)
+	}
+	if info.TagSHA != "1111111111111111111111111111111111111111" {
+		t.Errorf("Cache has wrong tag SHA: got %v, want the tag object SHA", info.TagSHA)
+	}
+}
+
+func TestLatestTagFallback(t *testing.T) {
+	cacheMutex.Lock()
+	releaseCache = make(map[string]ReleaseInfo)
+	cacheMutex.Unlock()
+
+	var mockServer *httptest.Server
+	mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/octo-org/tags-only/tags" && r.URL.Query().Get("page") == "2":
+			w.Write([]byte(`[{"name": "v1.2.0", "commit": {"sha": "3333333333333333333333333333333333333333"}}]`))
+		case r.URL.Path == "/repos/octo-org/tags-only/tags":
+			w.Header().Set("Link", `<`+mockServer.URL+`/repos/octo-org/tags-only/tags?page=2>; rel="next"`)
+			w.Write([]byte(`[
+				{"name": "nightly", "commit": {"sha": "4444444444444444444444444444444444444444"}},
+				{"name": "v2.0.0-rc.1", "commit": {"sha": "5555555555555555555555555555555555555555"}},
+				{"name": "v2.0.0-beta", "commit": {"sha": "6666666666666666666666666666666666666666"}},
+				{"name": "v1", "commit": {"sha": "1111111111111111111111111111111111111111"}},
+				{"name": "v1.10.0", "commit": {"sha": "1111111111111111111111111111111111111111"}},
+				{"name": "v1.9.3", "commit": {"sha": "2222222222222222222222222222222222222222"}}
+			]`))
+		case r.URL.Path == "/repos/octo-org/untagged/tags":
+			w.Write([]byte(`[{"name": "main-snapshot", "commit": {"sha": "4444444444444444444444444444444444444444"}}]`))
+		default:
+			// Neither repository publishes releases
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	mockURL, _ := url.Parse(mockServer.URL + "/")
+	mockClient := github.NewClient(nil)
+	mockClient.BaseURL = mockURL
+
+	version, sha, err := getLatestReleaseWithClient(mockClient, "octo-org/tags-only")
+	if err != nil {
+		t.Fatalf("getLatestReleaseWithClient() error = %v", err)
+	}
+	if version != "v1.10.0" || sha != "1111111111111111111111111111111111111111" {
+		t.Errorf("getLatestReleaseWithClient() = %v, %v, want the highest version tag v1.10.0", version, sha)
+	}
+
+	cacheMutex.RLock()
+	info := releaseCache["octo-org/tags-only"]
+	cacheMutex.RUnlock()
+	if info.FullVersion != "v1.10.0" || info.MajorVersion != "v1" || info.SHA != sha {
+		t.Errorf("Cache has wrong release info: got %+v", info)
+	}
+
+	version, sha, err = getLatestReleaseWithClient(mockClient, "octo-org/untagged")
+	if err != nil || version != "" || sha != "" {
+		t.Errorf("getLatestReleaseWithClient() without version tags = %v, %v, %v, want nothing", version, sha, err)
+	}
+}
+
+func TestLatestPrefixedRelease(t *testing.T) {
+	cacheMutex.Lock()
+	releaseCache = make(map[string]ReleaseInfo)
+	releaseListCache = make(map[string][]Release)
+	cacheMutex.Unlock()
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/aws-actions/toolkit/releases":
+			w.Write([]byte(`[
+				{"tag_name": "other-v2.0.0"},
+				{"tag_name": "deploy-v1.4.0-rc.1", "prerelease": true},
+				{"tag_name": "deploy-v1.3.0"},
+				{"tag_name": "deploy-v1.2.0"}
+			]`))
+		case "/repos/aws-actions/toolkit/git/ref/tags/deploy-v1.3.0":
+			w.Write([]byte(`{"ref": "refs/tags/deploy-v1.3.0", "object": {"sha": "3333333333333333333333333333333333333333", "type": "commit"}}`))
+		case "/repos/aws-actions/toolkit/tags":
+			w.Write([]byte(`[
+				{"name": "build-v2.1.0", "commit": {"sha": "4444444444444444444444444444444444444444"}},
+				{"name": "build-v2.0.0", "commit": {"sha": "5555555555555555555555555555555555555555"}},
+				{"name": "v9.0.0", "commit": {"sha": "6666666666666666666666666666666666666666"}}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	mockURL, _ := url.Parse(mockServer.URL + "/")
+	mockClient := github.NewClient(nil)
+	mockClient.BaseURL = mockURL
+
+	version, sha, err := getLatestPrefixedReleaseWithClient(mockClient, "aws-actions/toolkit/deploy", "deploy-")
+	if err != nil {
+		t.Fatalf("getLatestPrefixedReleaseWithClient() error = %v", err)
+	}
+	if version != "deploy-v1.3.0" || sha != "3333333333333333333333333333333333333333" {
+		t.Errorf("getLatestPrefixedReleaseWithClient() = %v, %v, want deploy-v1.3.0", version, sha)
+	}
+
+	// Actions without releases of their own fall back to their tags
+	version, sha, err = getLatestPrefixedReleaseWithClient(mockClient, "aws-actions/toolkit/build", "build-")
+	if err != nil {
+		t.Fatalf("getLatestPrefixedReleaseWithClient() error = %v", err)
+	}
+	if version != "build-v2.1.0" || sha != "4444444444444444444444444444444444444444" {
+		t.Errorf("getLatestPrefixedReleaseWithClient() = %v, %v, want build-v2.1.0", version, sha)
+	}
+
+	// Each prefix has its own cache entry, apart from the repository's latest release
+	cacheMutex.RLock()
+	deploy, build := releaseCache["aws-actions/toolkit@deploy-"], releaseCache["aws-actions/toolkit@build-"]
+	_, repository := releaseCache["aws-actions/toolkit"]
+	cacheMutex.RUnlock()
+	if deploy.FullVersion != "deploy-v1.3.0" || deploy.MajorVersion != "deploy-v1" {
+		t.Errorf("Cache has wrong release info for deploy-: got %+v", deploy)
+	}
+	if build.FullVersion != "build-v2.1.0" || build.MajorVersion != "build-v2" {
+		t.Errorf("Cache has wrong release info for build-: got %+v", build)
+	}
+	if repository {
+		t.Error("Cache has release info for the repository, want only the prefixes")
+	}
+}
+
 func TestExtractMajorVersion(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -238,6 +461,11 @@ func TestExtractMajorVersion(t *testing.T) {
 			version: "4",
 			want:    "4",
 		},
+		{
+			name:    "Prerelease",
+			version: "v5-beta",
+			want:    "v5",
+		},
 		{
 			name:    "Empty string",
 			version: "",
@@ -341,6 +569,11 @@ func TestIsMajorVersionConstraint(t *testing.T) {
 			version: "1.2.3",
 			want:    false,
 		},
+		{
+			name:    "Prerelease of a major version",
+			version: "v5-beta",
+			want:    false,
+		},
 		{
 			name:    "SHA",
 			version: "cdca7365b2dadb8aad0a33bc7601856ffabcc48e",
@@ -362,3 +595,50 @@ func TestIsMajorVersionConstraint(t *testing.T) {
 		})
 	}
 }
+
+func TestListReleases(t *testing.T) {
+	cacheMutex.Lock()
+	releaseListCache = make(map[string][]Release)
+	cacheMutex.Unlock()
+
+	var mockServer *httptest.Server
+	mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/actions/cache/releases" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`[{"tag_name": "v3.3.1", "published_at": "2024-01-02T03:04:05Z"}]`))
+			return
+		}
+		w.Header().Set("Link", `<`+mockServer.URL+`/repos/actions/cache/releases?page=2>; rel="next"`)
+		w.Write([]byte(`[
+			{"tag_name": "v4.1.0-beta", "prerelease": true, "published_at": "2025-02-03T04:05:06Z"},
+			{"tag_name": "v4.0.2", "published_at": "2025-01-02T03:04:05Z"},
+			{"tag_name": "v4.0.3", "draft": true}
+		]`))
+	}))
+	defer mockServer.Close()
+
+	mockURL, _ := url.Parse(mockServer.URL + "/")
+	mockClient := github.NewClient(nil)
+	mockClient.BaseURL = mockURL
+
+	got, err := listReleasesWithClient(mockClient, "actions/cache/save")
+	if err != nil {
+		t.Fatalf("listReleasesWithClient() error = %v", err)
+	}
+	want := []Release{
+		{Tag: "v4.1.0-beta", PublishedAt: time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC), Prerelease: true},
+		{Tag: "v4.0.2", PublishedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Tag: "v3.3.1", PublishedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("listReleasesWithClient() = %v, want %v", got, want)
+	}
+
+	cached, err := ListReleases("", "actions/cache")
+	if err != nil || !reflect.DeepEqual(cached, want) {
+		t.Errorf("ListReleases() = %v, %v, want cached %v", cached, err, want)
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// jwtLifetime is how long an app JWT is valid for. GitHub allows at most 10 minutes.
+	jwtLifetime = 9 * time.Minute
+	// jwtClockSkew backdates the JWT issue time to allow for clock drift.
+	jwtClockSkew = time.Minute
+	// tokenRefreshMargin is how long before expiry an installation token is refreshed.
+	tokenRefreshMargin = 5 * time.Minute
+)
+
+var (
+	appMutex sync.RWMutex
+	app      *appTransport
+)
+
+// SetApp authenticates requests to the default instance as an installation of a
+// GitHub App, instead of with a token. The private key is the PEM file that GitHub
+// generates for the app. Installation tokens are minted on first use and refreshed
+// before they expire.
+func SetApp(appID int64, installationID int64, privateKeyPEM []byte) error {
+	key, err := parsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return err
+	}
+
+	appMutex.Lock()
+	app = &appTransport{
+		appID:          appID,
+		installationID: installationID,
+		key:            key,
+		base:           http.DefaultTransport,
+		now:            time.Now,
+	}
+	appMutex.Unlock()
+
+	slog.Info("Using GitHub App authentication", "appID", appID, "installationID", installationID)
+	return nil
+}
+
+// appTransport is an http.RoundTripper that authenticates requests with an
+// installation token of a GitHub App.
+type appTransport struct {
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	base           http.RoundTripper
+	now            func() time.Time // Clock that JWTs are issued and tokens refreshed by
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// RoundTrip adds an installation token to the request, refreshing it if needed.
+func (t *appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.installationToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(req)
+}
+
+// installationToken returns a valid installation token, exchanging a new app JWT
+// for one if there is none or it is about to expire.
+func (t *appTransport) installationToken(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && t.now().Add(tokenRefreshMargin).Before(t.expiresAt) {
+		return t.token, nil
+	}
+
+	jwt, err := t.jwt()
+	if err != nil {
+		return "", err
+	}
+
+	h, _ := hostFor("")
+	tokenURL := h.apiURL().JoinPath("app", "installations", strconv.FormatInt(t.installationID, 10), "access_tokens")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("failed to create installation token for app %d: %s", t.appID, resp.Status)
+	}
+
+	var body struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode installation token: %w", err)
+	}
+	if body.Token == "" {
+		return "", errors.New("GitHub returned an empty installation token")
+	}
+
+	t.token, t.expiresAt = body.Token, body.ExpiresAt
+	slog.Debug("Refreshed GitHub App installation token", "installationID", t.installationID, "expiresAt", t.expiresAt)
+	return t.token, nil
+}
+
+// jwt creates a JSON Web Token signed with the app's private key, as described in
+// https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/generating-a-json-web-token-jwt-for-a-github-app
+func (t *appTransport) jwt() (string, error) {
+	issuedAt := t.now().Add(-jwtClockSkew)
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": issuedAt.Unix(),
+		"exp": issuedAt.Add(jwtLifetime).Unix(),
+		"iss": strconv.FormatInt(t.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, t.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses an RSA private key in PKCS #1 or PKCS #8 PEM form.
+func parsePrivateKey(privateKeyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return key, nil
+}
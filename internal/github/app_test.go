@@ -0,0 +1,157 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppAuthentication(t *testing.T) {
+	resetHosts(t)
+	t.Cleanup(func() {
+		appMutex.Lock()
+		app = nil
+		appMutex.Unlock()
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	start := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	clock := start
+
+	tokensIssued := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/app/installations/42/access_tokens":
+			if r.Method != http.MethodPost {
+				t.Errorf("access token request used %s, want POST", r.Method)
+			}
+			checkAppJWT(t, &key.PublicKey, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), clock)
+			tokensIssued++
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"token": "ghs_%d", "expires_at": %q}`, tokensIssued, clock.Add(time.Hour).Format(time.RFC3339))
+		case "/api/v3/repos/our-org/deploy/git/ref/tags/v1.0.0":
+			if got, want := r.Header.Get("Authorization"), fmt.Sprintf("Bearer ghs_%d", tokensIssued); got != want {
+				t.Errorf("Authorization = %q, want %q", got, want)
+			}
+			w.Write([]byte(`{"ref": "refs/tags/v1.0.0", "object": {"sha": "` + taggedSHA + `", "type": "commit"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	if err := SetDefaultURL(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetApp(1234, 42, keyPEM); err != nil {
+		t.Fatalf("SetApp() error = %v", err)
+	}
+	appMutex.Lock()
+	app.now = func() time.Time { return clock }
+	appMutex.Unlock()
+
+	for i := 0; i < 2; i++ {
+		if _, err := GetTagSHA("", "our-org/deploy", "v1.0.0"); err != nil {
+			t.Fatalf("GetTagSHA() error = %v", err)
+		}
+	}
+	if tokensIssued != 1 {
+		t.Errorf("issued %d installation tokens, want 1 reused token", tokensIssued)
+	}
+
+	// The token is refreshed shortly before it expires
+	clock = start.Add(56 * time.Minute)
+	if _, err := GetTagSHA("", "our-org/deploy", "v1.0.0"); err != nil {
+		t.Fatalf("GetTagSHA() error = %v", err)
+	}
+	if tokensIssued != 2 {
+		t.Errorf("issued %d installation tokens, want a refreshed token", tokensIssued)
+	}
+}
+
+// checkAppJWT verifies the signature and claims of an app JWT.
+func checkAppJWT(t *testing.T, key *rsa.PublicKey, jwt string, issued time.Time) {
+	t.Helper()
+
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("JWT has %d parts, want 3", len(parts))
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("JWT signature is invalid: %v", err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims struct {
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+		Issuer    string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.Issuer != "1234" {
+		t.Errorf("JWT issuer = %q, want 1234", claims.Issuer)
+	}
+	if claims.IssuedAt > issued.Unix() || claims.ExpiresAt-claims.IssuedAt > 600 {
+		t.Errorf("JWT is valid from %d to %d, want at most 10 minutes starting before %d", claims.IssuedAt, claims.ExpiresAt, issued.Unix())
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := parsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})); err != nil {
+		t.Errorf("parsePrivateKey() PKCS #8 error = %v", err)
+	}
+	if _, err := parsePrivateKey([]byte("not a key")); err == nil {
+		t.Error("parsePrivateKey() accepted a key that is not PEM encoded")
+	}
+}
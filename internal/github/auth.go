@@ -0,0 +1,133 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// tokenEnvVars are the environment variables checked for a token for the default
+// instance, in order.
+var tokenEnvVars = []string{"GITHUB_TOKEN", "GH_TOKEN"}
+
+var (
+	tokensMutex sync.Mutex
+	tokenCache  = make(map[string]string)
+)
+
+// resolveToken returns the token to use for an instance. An explicit token (from
+// --token) is used as is. Otherwise the default instance falls back to the
+// GITHUB_TOKEN and GH_TOKEN environment variables, and every instance falls back to
+// the token the gh CLI stored for its host. The source of each token is logged once
+// per instance, but never the token itself.
+func resolveToken(explicit string, h *host, isDefault bool) string {
+	key := h.String() + "\x00" + explicit
+
+	tokensMutex.Lock()
+	defer tokensMutex.Unlock()
+	if token, found := tokenCache[key]; found {
+		return token
+	}
+
+	token, source := explicit, "--token"
+	if token == "" && isDefault {
+		for _, name := range tokenEnvVars {
+			if token = os.Getenv(name); token != "" {
+				source = name
+				break
+			}
+		}
+	}
+	if token == "" {
+		token, source = ghCLIToken(h.hostname()), "gh CLI hosts file"
+	}
+
+	if token == "" {
+		slog.Warn("No GitHub token found, using unauthenticated requests with a low rate limit",
+			"url", h.String(),
+			"hint", "Use --token, set GITHUB_TOKEN or GH_TOKEN, or log in with 'gh auth login'")
+	} else {
+		slog.Info("Using GitHub token", "url", h.String(), "source", source)
+	}
+
+	tokenCache[key] = token
+	return token
+}
+
+// ghCLIToken returns the token that the gh CLI stored in its hosts file for a host,
+// or an empty string if there is none. Tokens that gh keeps in the system keyring
+// are not available.
+func ghCLIToken(hostname string) string {
+	path := ghHostsFile()
+	if path == "" {
+		return ""
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Debug("Failed to read gh CLI hosts file", "path", path, "error", err)
+		}
+		return ""
+	}
+
+	var hosts map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}
+	if err := yamlv3.Unmarshal(content, &hosts); err != nil {
+		slog.Debug("Failed to parse gh CLI hosts file", "path", path, "error", err)
+		return ""
+	}
+	return hosts[hostname].OAuthToken
+}
+
+// ghHostsFile returns the path of the gh CLI hosts file, following the same lookup
+// as gh itself.
+func ghHostsFile() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "hosts.yml")
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh", "hosts.yml")
+	}
+	if dir := os.Getenv("AppData"); runtime.GOOS == "windows" && dir != "" {
+		return filepath.Join(dir, "GitHub CLI", "hosts.yml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gh", "hosts.yml")
+}
+
+// hostname returns the host name of the instance, as used by the gh CLI.
+func (h *host) hostname() string {
+	if h == nil {
+		return "github.com"
+	}
+	u, err := url.Parse(h.url)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,84 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveToken(t *testing.T) {
+	resetHosts(t)
+
+	configDir := os.Getenv("GH_CONFIG_DIR")
+	hosts := `github.com:
+    user: octocat
+    oauth_token: gho_public
+    git_protocol: https
+github.example.com:
+    oauth_token: gho_enterprise
+`
+	if err := os.WriteFile(filepath.Join(configDir, "hosts.yml"), []byte(hosts), 0600); err != nil {
+		t.Fatal(err)
+	}
+	enterprise, err := parseHost("https://github.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The gh CLI hosts file is the last resort
+	if got := resolveToken("", nil, true); got != "gho_public" {
+		t.Errorf("resolveToken() = %q, want the gh CLI token for github.com", got)
+	}
+	if got := resolveToken("", enterprise, false); got != "gho_enterprise" {
+		t.Errorf("resolveToken() = %q, want the gh CLI token for github.example.com", got)
+	}
+
+	// Environment variables only apply to the default instance
+	tokensMutex.Lock()
+	tokenCache = make(map[string]string)
+	tokensMutex.Unlock()
+	t.Setenv("GH_TOKEN", "gh_env")
+	if got := resolveToken("", nil, true); got != "gh_env" {
+		t.Errorf("resolveToken() = %q, want GH_TOKEN", got)
+	}
+	if got := resolveToken("", enterprise, false); got != "gho_enterprise" {
+		t.Errorf("resolveToken() = %q, want the gh CLI token for a routed instance", got)
+	}
+
+	tokensMutex.Lock()
+	tokenCache = make(map[string]string)
+	tokensMutex.Unlock()
+	t.Setenv("GITHUB_TOKEN", "github_env")
+	if got := resolveToken("", nil, true); got != "github_env" {
+		t.Errorf("resolveToken() = %q, want GITHUB_TOKEN to take precedence over GH_TOKEN", got)
+	}
+
+	// An explicit token always wins
+	if got := resolveToken("flag", nil, true); got != "flag" {
+		t.Errorf("resolveToken() = %q, want the explicit token", got)
+	}
+}
+
+func TestResolveTokenNone(t *testing.T) {
+	resetHosts(t)
+
+	if got := resolveToken("", nil, true); got != "" {
+		t.Errorf("resolveToken() = %q, want no token", got)
+	}
+}
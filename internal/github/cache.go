@@ -0,0 +1,137 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/behnh/actions-toolkit/internal/cache"
+)
+
+// cachedHeaders are the response headers kept in the disk cache. Rate limit headers
+// are left out, since they are stale by the time the entry is reused.
+var cachedHeaders = []string{"Content-Type", "ETag", "Last-Modified", "Link"}
+
+var (
+	diskCacheMutex sync.RWMutex
+	diskCache      *cache.Cache
+)
+
+// SetCache caches responses from the GitHub API in dir, so that they can be reused
+// across runs. Responses are reused as they are for ttl, and revalidated with
+// conditional requests after that, which do not count against the rate limit. An
+// empty dir disables the cache.
+func SetCache(dir string, ttl time.Duration) {
+	diskCacheMutex.Lock()
+	defer diskCacheMutex.Unlock()
+	if dir == "" {
+		diskCache = nil
+		return
+	}
+	diskCache = cache.New(dir, ttl)
+	slog.Debug("Using disk cache", "dir", dir, "ttl", ttl)
+}
+
+// cacheTransport is an http.RoundTripper that answers GET requests from the disk
+// cache, revalidating expired entries with their ETag.
+type cacheTransport struct {
+	cache *cache.Cache
+	next  http.RoundTripper
+}
+
+// RoundTrip returns the cached response to a request if it is fresh or still valid,
+// and otherwise sends the request and caches the response.
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+		return t.next.RoundTrip(req)
+	}
+
+	// The Accept header selects the media type, and with it the shape of the response
+	key := req.URL.String() + " " + req.Header.Get("Accept")
+	entry, fresh := t.cache.Get(key)
+	if fresh {
+		slog.Debug("Using cached response", "url", req.URL.String())
+		return cachedResponse(req, entry), nil
+	}
+
+	if entry != nil && entry.ETag() != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.ETag())
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		if entry == nil {
+			return resp, nil
+		}
+		resp.Body.Close()
+		slog.Debug("Revalidated cached response", "url", req.URL.String())
+		if err := t.cache.Put(key, entry); err != nil {
+			slog.Debug("Failed to update cache entry", "url", req.URL.String(), "error", err)
+		}
+		return cachedResponse(req, entry), nil
+	case http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		entry := &cache.Entry{URL: req.URL.String(), Header: make(http.Header), Body: body}
+		for _, name := range cachedHeaders {
+			if value := resp.Header.Get(name); value != "" {
+				entry.Header.Set(name, value)
+			}
+		}
+		if err := t.cache.Put(key, entry); err != nil {
+			slog.Debug("Failed to cache response", "url", req.URL.String(), "error", err)
+		}
+	}
+	return resp, nil
+}
+
+// cachedResponse builds a response to a request from a cache entry. It is marked with
+// X-From-Cache, so that go-github does not record its missing rate limit headers.
+func cachedResponse(req *http.Request, entry *cache.Entry) *http.Response {
+	header := entry.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set("X-From-Cache", "1")
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCacheTransport(t *testing.T) {
+	resetHosts(t)
+	t.Cleanup(func() { SetCache("", 0) })
+
+	var requests, revalidated int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidated++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("X-RateLimit-Remaining", "59")
+		w.Write([]byte(`{"ref": "refs/tags/v1.0.0", "object": {"sha": "` + taggedSHA + `", "type": "commit"}}`))
+	}))
+	defer server.Close()
+	if err := SetDefaultURL(server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	getTag := func() {
+		t.Helper()
+		sha, err := getTagSHAWithClient(newClient("", "our-org/deploy"), "our-org/deploy", "v1.0.0")
+		if err != nil {
+			t.Fatalf("getTagSHAWithClient() error = %v", err)
+		}
+		if sha != taggedSHA {
+			t.Errorf("getTagSHAWithClient() = %q, want %q", sha, taggedSHA)
+		}
+	}
+
+	// A fresh entry is used without a request
+	SetCache(t.TempDir(), time.Hour)
+	getTag()
+	getTag()
+	if requests != 1 {
+		t.Errorf("server received %d requests, want 1", requests)
+	}
+
+	// An expired entry is revalidated with its ETag
+	diskCacheMutex.Lock()
+	dir := diskCache.Dir()
+	diskCacheMutex.Unlock()
+	SetCache(dir, 0)
+	getTag()
+	if requests != 2 || revalidated != 1 {
+		t.Errorf("server received %d requests with %d revalidated, want 2 with 1 revalidated", requests, revalidated)
+	}
+}
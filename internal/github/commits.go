@@ -0,0 +1,214 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/behnh/actions-toolkit/internal/semver"
+	"github.com/google/go-github/v72/github"
+)
+
+// maxComparedTags limits how many tags a commit is compared with when checking whether
+// it belongs to a repository, since each comparison is a separate API call.
+const maxComparedTags = 10
+
+// GetTagSHA returns the commit SHA that a tag of a GitHub action points at, peeling
+// annotated tags. It returns an empty SHA if the tag does not exist.
+// The actionName should be in the format "org/repo/optional_subpath".
+func GetTagSHA(token string, actionName string, tag string) (string, error) {
+	return getTagSHAWithClient(newClient(token, actionName), actionName, tag)
+}
+
+// GetRefSHA returns the commit SHA that a tag or branch of a GitHub action points at,
+// peeling annotated tags. A tag takes precedence over a branch of the same name. It
+// returns an empty SHA if there is no such tag or branch.
+// The actionName should be in the format "org/repo/optional_subpath".
+func GetRefSHA(token string, actionName string, ref string) (string, error) {
+	return getRefSHAWithClient(newClient(token, actionName), actionName, ref)
+}
+
+// GetTagsForCommit returns the names of all tags of a GitHub action that point at
+// the given commit SHA.
+func GetTagsForCommit(token string, actionName string, sha string) ([]string, error) {
+	return getTagsForCommitWithClient(newClient(token, actionName), actionName, sha)
+}
+
+// IsCommitInRepository reports whether a commit SHA is reachable from the default
+// branch or the latest version tag of a major version of a GitHub action's repository,
+// which covers maintenance branches of older majors. GitHub serves commits from any
+// fork in the repository's network under the parent repository, so a SHA that is
+// not reachable from the repository itself most likely comes from a fork.
+func IsCommitInRepository(token string, actionName string, sha string) (bool, error) {
+	return isCommitInRepositoryWithClient(newClient(token, actionName), actionName, sha)
+}
+
+func getTagSHAWithClient(client *github.Client, actionName string, tag string) (string, error) {
+	owner, repo, ok := splitActionName(actionName)
+	if !ok {
+		return "", fmt.Errorf("invalid action name %q", actionName)
+	}
+
+	ctx := context.Background()
+	ref, resp, err := client.Git.GetRef(ctx, owner, repo, "refs/tags/"+tag)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			slog.Debug("Tag not found", "action", actionName, "tag", tag)
+			return "", nil
+		}
+		return "", err
+	}
+
+	sha, _, err := peelTag(ctx, client, owner, repo, ref.GetObject())
+	return sha, err
+}
+
+func getRefSHAWithClient(client *github.Client, actionName string, ref string) (string, error) {
+	sha, err := getTagSHAWithClient(client, actionName, ref)
+	if err != nil || sha != "" {
+		return sha, err
+	}
+
+	owner, repo, _ := splitActionName(actionName)
+	branch, resp, err := client.Git.GetRef(context.Background(), owner, repo, "refs/heads/"+ref)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			slog.Debug("Branch not found", "action", actionName, "branch", ref)
+			return "", nil
+		}
+		return "", err
+	}
+	return branch.GetObject().GetSHA(), nil
+}
+
+func getTagsForCommitWithClient(client *github.Client, actionName string, sha string) ([]string, error) {
+	owner, repo, ok := splitActionName(actionName)
+	if !ok {
+		return nil, fmt.Errorf("invalid action name %q", actionName)
+	}
+
+	tags, err := listTags(context.Background(), client, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, tag := range tags {
+		if tag.GetCommit().GetSHA() == sha {
+			names = append(names, tag.GetName())
+		}
+	}
+
+	return names, nil
+}
+
+func isCommitInRepositoryWithClient(client *github.Client, actionName string, sha string) (bool, error) {
+	owner, repo, ok := splitActionName(actionName)
+	if !ok {
+		return false, fmt.Errorf("invalid action name %q", actionName)
+	}
+
+	ctx := context.Background()
+	repository, _, err := client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return false, err
+	}
+
+	// Most pinned commits are on the default branch, so check it first
+	refs := []string{repository.GetDefaultBranch()}
+
+	tags, err := listTags(ctx, client, owner, repo)
+	if err != nil {
+		return false, err
+	}
+	for _, tag := range comparedTags(tags) {
+		refs = append(refs, "refs/tags/"+tag)
+	}
+
+	for _, ref := range refs {
+		// The commit is reachable from ref if ref is identical to or ahead of it
+		comparison, resp, err := client.Repositories.CompareCommits(ctx, owner, repo, ref, sha, &github.ListOptions{PerPage: 1})
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return false, nil
+			}
+			return false, err
+		}
+
+		status := comparison.GetStatus()
+		slog.Debug("Compared commit with ref", "action", actionName, "sha", sha, "ref", ref, "status", status)
+		if status == "identical" || status == "behind" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// comparedTags returns the tags that a commit is compared with when checking whether it
+// belongs to a repository: the highest version tag of each major version, newest major
+// first and at most maxComparedTags of them. Older tags of a major are normally
+// reachable from its highest one, so comparing with them as well rarely helps.
+func comparedTags(tags []*github.RepositoryTag) []string {
+	latest := make(map[int]semver.Version)
+	names := make(map[int]string)
+	for _, tag := range tags {
+		version, ok := semver.Parse(tag.GetName())
+		if !ok || version.IsPrerelease() {
+			continue
+		}
+		if current, found := latest[version.Major]; found && semver.Compare(version, current) <= 0 {
+			continue
+		}
+		latest[version.Major] = version
+		names[version.Major] = tag.GetName()
+	}
+
+	majors := make([]int, 0, len(names))
+	for major := range names {
+		majors = append(majors, major)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(majors)))
+
+	var compared []string
+	for _, major := range majors[:min(len(majors), maxComparedTags)] {
+		compared = append(compared, names[major])
+	}
+	return compared
+}
+
+// listTags returns all tags of a repository, following pagination.
+func listTags(ctx context.Context, client *github.Client, owner, repo string) ([]*github.RepositoryTag, error) {
+	var tags []*github.RepositoryTag
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := client.Repositories.ListTags(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return tags, nil
+}
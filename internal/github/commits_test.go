@@ -0,0 +1,207 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v72/github"
+)
+
+const (
+	taggedSHA   = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	untaggedSHA = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	forkSHA     = "cccccccccccccccccccccccccccccccccccccccc"
+)
+
+func newCommitsTestClient(t *testing.T) *github.Client {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/actions/checkout/git/ref/tags/v4.2.2":
+			w.Write([]byte(`{"ref": "refs/tags/v4.2.2", "object": {"sha": "` + taggedSHA + `", "type": "commit"}}`))
+		case "/repos/actions/checkout/git/ref/heads/main":
+			w.Write([]byte(`{"ref": "refs/heads/main", "object": {"sha": "` + untaggedSHA + `", "type": "commit"}}`))
+		case "/repos/actions/checkout/tags":
+			w.Write([]byte(`[
+				{"name": "v4.2.2", "commit": {"sha": "` + taggedSHA + `"}},
+				{"name": "v4", "commit": {"sha": "` + taggedSHA + `"}},
+				{"name": "v4.2.1", "commit": {"sha": "dddddddddddddddddddddddddddddddddddddddd"}}
+			]`))
+		case "/repos/actions/checkout":
+			w.Write([]byte(`{"default_branch": "main"}`))
+		case "/repos/actions/checkout/compare/main..." + untaggedSHA:
+			w.Write([]byte(`{"status": "behind"}`))
+		case "/repos/actions/checkout/compare/main..." + forkSHA,
+			"/repos/actions/checkout/compare/refs/tags/v4.2.2..." + forkSHA,
+			"/repos/actions/checkout/compare/refs/tags/v4..." + forkSHA,
+			"/repos/actions/checkout/compare/refs/tags/v4.2.1..." + forkSHA:
+			w.Write([]byte(`{"status": "diverged"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(mockServer.Close)
+
+	mockURL, _ := url.Parse(mockServer.URL + "/")
+	mockClient := github.NewClient(nil)
+	mockClient.BaseURL = mockURL
+	return mockClient
+}
+
+func TestGetTagSHA(t *testing.T) {
+	client := newCommitsTestClient(t)
+
+	sha, err := getTagSHAWithClient(client, "actions/checkout", "v4.2.2")
+	if err != nil {
+		t.Fatalf("getTagSHAWithClient() error = %v", err)
+	}
+	if sha != taggedSHA {
+		t.Errorf("getTagSHAWithClient() = %v, want %v", sha, taggedSHA)
+	}
+
+	// Missing tags resolve to an empty SHA rather than an error
+	sha, err = getTagSHAWithClient(client, "actions/checkout", "v9.9.9")
+	if err != nil {
+		t.Fatalf("getTagSHAWithClient() error = %v", err)
+	}
+	if sha != "" {
+		t.Errorf("getTagSHAWithClient() = %v, want empty SHA", sha)
+	}
+}
+
+func TestGetRefSHA(t *testing.T) {
+	client := newCommitsTestClient(t)
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"v4.2.2", taggedSHA},
+		{"main", untaggedSHA},
+		{"missing", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			sha, err := getRefSHAWithClient(client, "actions/checkout", tt.ref)
+			if err != nil {
+				t.Fatalf("getRefSHAWithClient() error = %v", err)
+			}
+			if sha != tt.want {
+				t.Errorf("getRefSHAWithClient() = %v, want %v", sha, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTagsForCommit(t *testing.T) {
+	client := newCommitsTestClient(t)
+
+	tags, err := getTagsForCommitWithClient(client, "actions/checkout", taggedSHA)
+	if err != nil {
+		t.Fatalf("getTagsForCommitWithClient() error = %v", err)
+	}
+	if !reflect.DeepEqual(tags, []string{"v4.2.2", "v4"}) {
+		t.Errorf("getTagsForCommitWithClient() = %v, want %v", tags, []string{"v4.2.2", "v4"})
+	}
+}
+
+func TestIsCommitInRepository(t *testing.T) {
+	client := newCommitsTestClient(t)
+
+	tests := []struct {
+		name string
+		sha  string
+		want bool
+	}{
+		{
+			name: "Commit on the default branch",
+			sha:  untaggedSHA,
+			want: true,
+		},
+		{
+			name: "Commit from a fork",
+			sha:  forkSHA,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isCommitInRepositoryWithClient(client, "actions/checkout", tt.sha)
+			if err != nil {
+				t.Fatalf("isCommitInRepositoryWithClient() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("isCommitInRepositoryWithClient() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsCommitInRepositoryManyTags(t *testing.T) {
+	// 30 majors with 10 tags each, and a commit from a fork that no ref contains
+	var tags []string
+	for major := 1; major <= 30; major++ {
+		for patch := range 10 {
+			tags = append(tags, fmt.Sprintf(`{"name": "v%d.0.%d", "commit": {"sha": "%040d"}}`, major, patch, major*100+patch))
+		}
+	}
+
+	var compared []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/actions/monorepo":
+			w.Write([]byte(`{"default_branch": "main"}`))
+		case r.URL.Path == "/repos/actions/monorepo/tags":
+			w.Write([]byte("[" + strings.Join(tags, ",") + "]"))
+		case strings.HasPrefix(r.URL.Path, "/repos/actions/monorepo/compare/"):
+			ref := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/repos/actions/monorepo/compare/"), "..."+forkSHA)
+			compared = append(compared, ref)
+			w.Write([]byte(`{"status": "diverged"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(mockServer.Close)
+
+	mockURL, _ := url.Parse(mockServer.URL + "/")
+	client := github.NewClient(nil)
+	client.BaseURL = mockURL
+
+	got, err := isCommitInRepositoryWithClient(client, "actions/monorepo", forkSHA)
+	if err != nil {
+		t.Fatalf("isCommitInRepositoryWithClient() error = %v", err)
+	}
+	if got {
+		t.Errorf("isCommitInRepositoryWithClient() = %v, want false", got)
+	}
+
+	// The default branch, then the latest tag of the newest majors
+	if len(compared) != 1+maxComparedTags {
+		t.Fatalf("compared %d refs, want %d: %v", len(compared), 1+maxComparedTags, compared)
+	}
+	if compared[0] != "main" || compared[1] != "refs/tags/v30.0.9" || compared[maxComparedTags] != "refs/tags/v21.0.9" {
+		t.Errorf("compared refs = %v", compared)
+	}
+}
@@ -0,0 +1,271 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v72/github"
+)
+
+// graphQLBatchSize is how many repositories are resolved in a single GraphQL query,
+// which keeps each query well within GraphQL's node limits.
+const graphQLBatchSize = 50
+
+// releaseBatcher resolves the latest releases of many repositories at once.
+type releaseBatcher interface {
+	// LatestReleases returns the latest release of each repository (owner/repo) that
+	// has one. Repositories without a release are left out.
+	LatestReleases(ctx context.Context, repos []string) (map[string]ReleaseInfo, error)
+}
+
+// graphQLBatcher resolves latest releases with aliased GraphQL queries, which fetch
+// the release, its tag and the commit the tag points at for a whole batch of
+// repositories in a single request. The GraphQL API requires authentication.
+type graphQLBatcher struct {
+	client *github.Client
+}
+
+// restBatcher resolves latest releases with the REST API, which takes at least two
+// requests per repository.
+type restBatcher struct {
+	client *github.Client
+}
+
+// fallbackBatcher resolves latest releases with primary, and with fallback if that fails.
+type fallbackBatcher struct {
+	primary  releaseBatcher
+	fallback releaseBatcher
+}
+
+// PrefetchLatestReleases resolves the latest releases of many actions ahead of
+// GetLatestReleaseWithSHA, which then answers from its cache. Actions are resolved
+// through the GraphQL API in batches, for each GitHub instance they are routed to, and
+// through the REST API if a query fails. Without credentials for an instance, its
+// actions are left to be resolved as they are needed.
+func PrefetchLatestReleases(token string, actions []string) {
+	// Group the repositories that are not cached yet by instance, keeping an action of
+	// each instance to create its client
+	groups := make(map[string][]string)
+	clientActions := make(map[string]string)
+	seen := make(map[string]bool)
+	cacheMutex.RLock()
+	for _, action := range actions {
+		if _, _, ok := splitActionName(action); !ok {
+			continue
+		}
+		base := getBaseActionName(action)
+		if _, found := releaseCache[base]; found || seen[base] {
+			continue
+		}
+		seen[base] = true
+		h, _ := hostFor(action)
+		groups[h.String()] = append(groups[h.String()], base)
+		clientActions[h.String()] = action
+	}
+	cacheMutex.RUnlock()
+
+	instances := make([]string, 0, len(groups))
+	for instance := range groups {
+		instances = append(instances, instance)
+	}
+	sort.Strings(instances)
+
+	for _, instance := range instances {
+		client, authenticated := newAuthenticatedClient(token, clientActions[instance])
+		if !authenticated {
+			slog.Debug("Not batching release lookups, the GraphQL API requires authentication", "url", instance)
+			continue
+		}
+
+		var batcher releaseBatcher = fallbackBatcher{
+			primary:  graphQLBatcher{client: client},
+			fallback: restBatcher{client: client},
+		}
+		repos := groups[instance]
+		releases, err := batcher.LatestReleases(context.Background(), repos)
+		if err != nil {
+			slog.Debug("Failed to prefetch releases", "url", instance, "error", err)
+			continue
+		}
+		slog.Debug("Prefetched releases", "url", instance, "repositories", len(repos), "releases", len(releases))
+		storeReleases(releases)
+	}
+}
+
+// storeReleases adds resolved releases to the release cache.
+func storeReleases(releases map[string]ReleaseInfo) {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	for repo, info := range releases {
+		releaseCache[repo] = info
+	}
+}
+
+// LatestReleases resolves each repository with its own REST requests, one after another.
+func (r restBatcher) LatestReleases(ctx context.Context, repos []string) (map[string]ReleaseInfo, error) {
+	releases := make(map[string]ReleaseInfo)
+	for _, repo := range repos {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		version, _, err := getLatestReleaseWithClient(r.client, repo)
+		if err != nil {
+			return nil, err
+		}
+		if version == "" {
+			continue
+		}
+		// getLatestReleaseWithClient caches everything it resolved, including the tag object
+		cacheMutex.RLock()
+		releases[repo] = releaseCache[repo]
+		cacheMutex.RUnlock()
+	}
+	return releases, nil
+}
+
+// LatestReleases resolves the repositories with primary, or with fallback if that fails.
+func (r fallbackBatcher) LatestReleases(ctx context.Context, repos []string) (map[string]ReleaseInfo, error) {
+	releases, err := r.primary.LatestReleases(ctx, repos)
+	if err == nil {
+		return releases, nil
+	}
+	slog.Debug("Failed to resolve releases, falling back", "error", err)
+	return r.fallback.LatestReleases(ctx, repos)
+}
+
+// graphQLRequest is the body of a GraphQL request.
+type graphQLRequest struct {
+	Query string `json:"query"`
+}
+
+// graphQLResponse is the body of a GraphQL response to a latest release query, with
+// a repository for each alias.
+type graphQLResponse struct {
+	Data   map[string]*graphQLRepository `json:"data"`
+	Errors []graphQLError                `json:"errors"`
+}
+
+type graphQLError struct {
+	Type    string   `json:"type"`
+	Path    []string `json:"path"`
+	Message string   `json:"message"`
+}
+
+type graphQLRepository struct {
+	NameWithOwner string `json:"nameWithOwner"`
+	LatestRelease *struct {
+		TagName string `json:"tagName"`
+		Tag     *struct {
+			Target struct {
+				Type string `json:"__typename"`
+				OID  string `json:"oid"`
+			} `json:"target"`
+		} `json:"tag"`
+		TagCommit *struct {
+			OID string `json:"oid"`
+		} `json:"tagCommit"`
+	} `json:"latestRelease"`
+}
+
+// LatestReleases resolves the repositories in batches of graphQLBatchSize. Release tags
+// are peeled by GitHub, so annotated tags need no further requests.
+func (r graphQLBatcher) LatestReleases(ctx context.Context, repos []string) (map[string]ReleaseInfo, error) {
+	endpoint := graphQLURL(r.client.BaseURL)
+	releases := make(map[string]ReleaseInfo)
+
+	for start := 0; start < len(repos); start += graphQLBatchSize {
+		batch := repos[start:min(start+graphQLBatchSize, len(repos))]
+		req, err := r.client.NewRequest("POST", endpoint, graphQLRequest{Query: latestReleasesQuery(batch)})
+		if err != nil {
+			return nil, err
+		}
+
+		var resp graphQLResponse
+		if _, err := r.client.Do(ctx, req, &resp); err != nil {
+			return nil, err
+		}
+
+		// Repositories that do not exist are reported as NOT_FOUND errors, and left to
+		// the REST API to report in the same way as other lookups
+		for _, e := range resp.Errors {
+			if e.Type != "NOT_FOUND" {
+				return nil, fmt.Errorf("GraphQL query failed: %s", e.Message)
+			}
+		}
+
+		for i, repo := range batch {
+			result := resp.Data["r"+strconv.Itoa(i)]
+			if result == nil || result.LatestRelease == nil || result.LatestRelease.TagCommit == nil {
+				continue
+			}
+			release := result.LatestRelease
+			info := ReleaseInfo{
+				MajorVersion: extractMajorVersion(release.TagName),
+				FullVersion:  release.TagName,
+				SHA:          release.TagCommit.OID,
+			}
+			if release.Tag != nil && release.Tag.Target.Type == "Tag" {
+				info.TagSHA = release.Tag.Target.OID
+			}
+			releases[repo] = info
+		}
+	}
+	return releases, nil
+}
+
+// latestReleasesQuery builds a query for the latest release of each repository, aliased
+// r0, r1 and so on in the order of repos.
+func latestReleasesQuery(repos []string) string {
+	var query strings.Builder
+	query.WriteString("query {\n")
+	for i, repo := range repos {
+		owner, name, _ := splitActionName(repo)
+		fmt.Fprintf(&query, "  r%d: repository(owner: %s, name: %s) { ...release }\n", i, strconv.Quote(owner), strconv.Quote(name))
+	}
+	query.WriteString(`}
+
+fragment release on Repository {
+  nameWithOwner
+  latestRelease {
+    tagName
+    tag { target { __typename oid } }
+    tagCommit { oid }
+  }
+}
+`)
+	return query.String()
+}
+
+// graphQLURL returns the GraphQL endpoint of the instance with the given REST API base
+// URL: https://api.github.com/graphql for github.com, and /api/graphql on GitHub
+// Enterprise Server, whose REST API is under /api/v3/.
+func graphQLURL(baseURL *url.URL) string {
+	u := *baseURL
+	if strings.HasSuffix(u.Path, "/api/v3/") {
+		u.Path = strings.TrimSuffix(u.Path, "v3/") + "graphql"
+	} else {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/graphql"
+	}
+	return u.String()
+}
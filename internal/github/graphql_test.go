@@ -0,0 +1,174 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const annotatedTagSHA = "eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"
+
+func resetReleaseCache(t *testing.T) {
+	t.Helper()
+	reset := func() {
+		cacheMutex.Lock()
+		releaseCache = make(map[string]ReleaseInfo)
+		cacheMutex.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestPrefetchLatestReleases(t *testing.T) {
+	resetHosts(t)
+	resetReleaseCache(t)
+	t.Setenv("GITHUB_TOKEN", "ghp_test")
+
+	var queries []string
+	restRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/graphql" {
+			restRequests++
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer ghp_test" {
+			t.Errorf("Authorization = %q, want the token", got)
+		}
+		var body graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		queries = append(queries, body.Query)
+		w.Write([]byte(`{
+			"data": {
+				"r0": {"nameWithOwner": "actions/checkout", "latestRelease": {"tagName": "v4.2.2", "tag": {"target": {"__typename": "Commit", "oid": "` + taggedSHA + `"}}, "tagCommit": {"oid": "` + taggedSHA + `"}}},
+				"r1": {"nameWithOwner": "actions/cache", "latestRelease": {"tagName": "v4.2.0", "tag": {"target": {"__typename": "Tag", "oid": "` + annotatedTagSHA + `"}}, "tagCommit": {"oid": "` + untaggedSHA + `"}}},
+				"r2": {"nameWithOwner": "our-org/no-releases", "latestRelease": null},
+				"r3": null
+			},
+			"errors": [{"type": "NOT_FOUND", "path": ["r3"], "message": "Could not resolve to a Repository with the name 'our-org/missing'."}]
+		}`))
+	}))
+	defer server.Close()
+	if err := SetDefaultURL(server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	PrefetchLatestReleases("", []string{"actions/checkout", "actions/cache/save", "actions/cache/restore", "our-org/no-releases", "our-org/missing"})
+
+	if len(queries) != 1 {
+		t.Fatalf("sent %d GraphQL queries, want 1", len(queries))
+	}
+	for _, want := range []string{`r0: repository(owner: "actions", name: "checkout")`, `r1: repository(owner: "actions", name: "cache")`, `r3: repository(owner: "our-org", name: "missing")`} {
+		if !strings.Contains(queries[0], want) {
+			t.Errorf("query does not contain %q:\n%s", want, queries[0])
+		}
+	}
+
+	// The prefetched releases are used without further requests
+	version, sha, err := GetLatestReleaseWithSHA("", "actions/cache/save", "v4")
+	if err != nil || version != "v4.2.0" || sha != untaggedSHA {
+		t.Errorf("GetLatestReleaseWithSHA() = %q, %q, %v, want v4.2.0, %s", version, sha, err, untaggedSHA)
+	}
+	cacheMutex.RLock()
+	info := releaseCache["actions/cache"]
+	_, cachedMissing := releaseCache["our-org/missing"]
+	cacheMutex.RUnlock()
+	if info.TagSHA != annotatedTagSHA || info.MajorVersion != "v4" {
+		t.Errorf("cached release = %+v, want tag object %s and major version v4", info, annotatedTagSHA)
+	}
+	if cachedMissing {
+		t.Error("a repository that does not exist was cached")
+	}
+	if restRequests != 0 {
+		t.Errorf("sent %d REST requests, want 0", restRequests)
+	}
+}
+
+func TestPrefetchLatestReleasesFallback(t *testing.T) {
+	resetHosts(t)
+	resetReleaseCache(t)
+	t.Setenv("GITHUB_TOKEN", "ghp_test")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/graphql":
+			w.Write([]byte(`{"data": null, "errors": [{"type": "FORBIDDEN", "message": "Resource protected by organization SAML enforcement."}]}`))
+		case "/api/v3/repos/actions/checkout/releases/latest":
+			w.Write([]byte(`{"tag_name": "v4.2.2"}`))
+		case "/api/v3/repos/actions/checkout/git/ref/tags/v4.2.2":
+			w.Write([]byte(`{"ref": "refs/tags/v4.2.2", "object": {"sha": "` + taggedSHA + `", "type": "commit"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	if err := SetDefaultURL(server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	PrefetchLatestReleases("", []string{"actions/checkout", "actions/setup-node"})
+
+	cacheMutex.RLock()
+	info := releaseCache["actions/checkout"]
+	cacheMutex.RUnlock()
+	if info.FullVersion != "v4.2.2" || info.SHA != taggedSHA {
+		t.Errorf("cached release = %+v, want v4.2.2 at %s from the REST API", info, taggedSHA)
+	}
+}
+
+func TestPrefetchLatestReleasesUnauthenticated(t *testing.T) {
+	resetHosts(t)
+	resetReleaseCache(t)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	if err := SetDefaultURL(server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	PrefetchLatestReleases("", []string{"actions/checkout", "actions/cache"})
+	if requests != 0 {
+		t.Errorf("sent %d requests without credentials, want 0", requests)
+	}
+}
+
+func TestGraphQLURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{"https://api.github.com/", "https://api.github.com/graphql"},
+		{"https://github.example.com/api/v3/", "https://github.example.com/api/graphql"},
+	}
+	for _, tt := range tests {
+		baseURL, _ := url.Parse(tt.baseURL)
+		if got := graphQLURL(baseURL); got != tt.want {
+			t.Errorf("graphQLURL(%q) = %q, want %q", tt.baseURL, got, tt.want)
+		}
+	}
+}
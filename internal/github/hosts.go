@@ -0,0 +1,174 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v72/github"
+)
+
+// defaultAPIURL is the REST API base URL of github.com.
+const defaultAPIURL = "https://api.github.com/"
+
+// host is a GitHub instance that actions can be resolved against. A nil host is github.com.
+type host struct {
+	url       string   // The instance URL as configured (e.g., https://github.example.com)
+	baseURL   *url.URL // The REST API base URL
+	uploadURL *url.URL // The uploads API base URL
+}
+
+var (
+	hostsMutex  sync.RWMutex
+	defaultHost *host
+	ownerHosts  = make(map[string]*host)
+)
+
+// SetDefaultURL sets the GitHub instance that actions are resolved against unless
+// their owner is routed elsewhere. An empty URL or https://github.com means github.com.
+func SetDefaultURL(rawURL string) error {
+	h, err := parseHost(rawURL)
+	if err != nil {
+		return err
+	}
+	hostsMutex.Lock()
+	defaultHost = h
+	hostsMutex.Unlock()
+	return nil
+}
+
+// SetOwnerURL routes the actions of an owner (e.g., our-org) to a GitHub instance,
+// which may also be github.com when the default instance is GitHub Enterprise Server.
+func SetOwnerURL(owner string, rawURL string) error {
+	if owner == "" || strings.Contains(owner, "/") {
+		return fmt.Errorf("invalid owner %q", owner)
+	}
+	h, err := parseHost(rawURL)
+	if err != nil {
+		return err
+	}
+	hostsMutex.Lock()
+	ownerHosts[strings.ToLower(owner)] = h
+	hostsMutex.Unlock()
+	return nil
+}
+
+// newClient creates a GitHub client for the instance that an action is resolved
+// against. The given token is only sent to the default instance, so that a token for
+// one instance is never sent to another; other instances use the token resolved for them.
+func newClient(token string, actionName string) *github.Client {
+	client, _ := newAuthenticatedClient(token, actionName)
+	return client
+}
+
+// newAuthenticatedClient is newClient, but also reports whether the client sends
+// credentials, which some APIs such as GraphQL require.
+func newAuthenticatedClient(token string, actionName string) (*github.Client, bool) {
+	h, isDefault := hostFor(actionName)
+	if !isDefault && token != "" {
+		slog.Debug("Not sending token to a routed GitHub instance", "action", actionName, "url", h.String())
+		token = ""
+	}
+
+	appMutex.RLock()
+	appAuth := app
+	appMutex.RUnlock()
+
+	var transport http.RoundTripper = http.DefaultTransport
+	useApp := appAuth != nil && isDefault && token == ""
+	if useApp {
+		transport = appAuth
+	}
+
+	transport = &retryTransport{next: transport}
+
+	diskCacheMutex.RLock()
+	if diskCache != nil {
+		transport = &cacheTransport{cache: diskCache, next: transport}
+	}
+	diskCacheMutex.RUnlock()
+
+	client := github.NewClient(&http.Client{Transport: transport})
+	authenticated := useApp
+	if !useApp {
+		if token = resolveToken(token, h, isDefault); token != "" {
+			client = client.WithAuthToken(token)
+			authenticated = true
+		}
+	}
+	if h != nil {
+		baseURL, uploadURL := *h.baseURL, *h.uploadURL
+		client.BaseURL, client.UploadURL = &baseURL, &uploadURL
+	}
+	return client, authenticated
+}
+
+// hostFor returns the instance an action is resolved against, and whether it is the
+// default instance.
+func hostFor(actionName string) (*host, bool) {
+	owner, _, _ := strings.Cut(actionName, "/")
+
+	hostsMutex.RLock()
+	defer hostsMutex.RUnlock()
+	if h, found := ownerHosts[strings.ToLower(owner)]; found {
+		return h, h.String() == defaultHost.String()
+	}
+	return defaultHost, true
+}
+
+// parseHost parses the URL of a GitHub instance, returning nil for github.com.
+func parseHost(rawURL string) (*host, error) {
+	if rawURL == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("invalid GitHub URL %q, expected e.g. https://github.example.com", rawURL)
+	}
+	if u.Host == "github.com" || u.Host == "api.github.com" {
+		return nil, nil
+	}
+
+	client, err := github.NewClient(nil).WithEnterpriseURLs(rawURL, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &host{url: strings.TrimSuffix(rawURL, "/"), baseURL: client.BaseURL, uploadURL: client.UploadURL}, nil
+}
+
+// apiURL returns the REST API base URL of the instance.
+func (h *host) apiURL() *url.URL {
+	if h == nil {
+		u, _ := url.Parse(defaultAPIURL)
+		return u
+	}
+	u := *h.baseURL
+	return &u
+}
+
+// String returns the URL of the instance.
+func (h *host) String() string {
+	if h == nil {
+		return "https://github.com"
+	}
+	return h.url
+}
@@ -0,0 +1,135 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func resetHosts(t *testing.T) {
+	t.Helper()
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	for _, name := range tokenEnvVars {
+		t.Setenv(name, "")
+	}
+	reset := func() {
+		hostsMutex.Lock()
+		defaultHost = nil
+		ownerHosts = make(map[string]*host)
+		hostsMutex.Unlock()
+
+		tokensMutex.Lock()
+		tokenCache = make(map[string]string)
+		tokensMutex.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestNewClientRouting(t *testing.T) {
+	resetHosts(t)
+
+	if err := SetOwnerURL("our-org", "https://github.example.com"); err != nil {
+		t.Fatalf("SetOwnerURL() error = %v", err)
+	}
+
+	tests := []struct {
+		actionName string
+		want       string
+	}{
+		{"actions/checkout", "https://api.github.com/"},
+		{"our-org/deploy", "https://github.example.com/api/v3/"},
+		{"Our-Org/deploy/sub", "https://github.example.com/api/v3/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.actionName, func(t *testing.T) {
+			if got := newClient("token", tt.actionName).BaseURL.String(); got != tt.want {
+				t.Errorf("newClient().BaseURL = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Routing github.com back in when the default instance is GitHub Enterprise Server
+	if err := SetDefaultURL("https://github.example.com/"); err != nil {
+		t.Fatalf("SetDefaultURL() error = %v", err)
+	}
+	if err := SetOwnerURL("actions", "https://github.com"); err != nil {
+		t.Fatalf("SetOwnerURL() error = %v", err)
+	}
+	if got := newClient("token", "actions/checkout").BaseURL.String(); got != "https://api.github.com/" {
+		t.Errorf("newClient().BaseURL = %v, want github.com", got)
+	}
+	if got := newClient("token", "other-org/tool").BaseURL.String(); got != "https://github.example.com/api/v3/" {
+		t.Errorf("newClient().BaseURL = %v, want the default instance", got)
+	}
+}
+
+func TestNewClientToken(t *testing.T) {
+	resetHosts(t)
+
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if r.URL.Path != "/api/v3/repos/our-org/deploy/git/ref/tags/v1.0.0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"ref": "refs/tags/v1.0.0", "object": {"sha": "` + taggedSHA + `", "type": "commit"}}`))
+	}))
+	defer server.Close()
+
+	// The token belongs to the default instance, and is not sent elsewhere
+	if err := SetOwnerURL("our-org", server.URL); err != nil {
+		t.Fatalf("SetOwnerURL() error = %v", err)
+	}
+	sha, err := GetTagSHA("secret", "our-org/deploy", "v1.0.0")
+	if err != nil || sha != taggedSHA {
+		t.Fatalf("GetTagSHA() = %v, %v, want %v", sha, err, taggedSHA)
+	}
+	if authorization != "" {
+		t.Errorf("token was sent to a routed instance: %q", authorization)
+	}
+
+	if err := SetDefaultURL(server.URL); err != nil {
+		t.Fatalf("SetDefaultURL() error = %v", err)
+	}
+	if _, err := GetTagSHA("secret", "our-org/deploy", "v1.0.0"); err != nil {
+		t.Fatalf("GetTagSHA() error = %v", err)
+	}
+	if authorization != "Bearer secret" {
+		t.Errorf("Authorization = %q, want the token for the default instance", authorization)
+	}
+}
+
+func TestParseHost(t *testing.T) {
+	for _, rawURL := range []string{"", "https://github.com", "https://api.github.com/"} {
+		if h, err := parseHost(rawURL); err != nil || h != nil {
+			t.Errorf("parseHost(%q) = %v, %v, want github.com", rawURL, h, err)
+		}
+	}
+	for _, rawURL := range []string{"github.example.com", "ftp://github.example.com", "https://"} {
+		if _, err := parseHost(rawURL); err == nil {
+			t.Errorf("parseHost(%q) succeeded, want an error", rawURL)
+		}
+	}
+	if err := SetOwnerURL("a/b", "https://github.example.com"); err == nil {
+		t.Error("SetOwnerURL() accepted an owner with a slash")
+	}
+}
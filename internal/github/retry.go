@@ -0,0 +1,174 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+)
+
+var (
+	// maxRetries is how many times a request is retried after a server error or rate
+	// limit.
+	maxRetries = 3
+	// retryBaseDelay is the delay before the first retry, doubled for each retry after it.
+	retryBaseDelay = time.Second
+	// maxRateLimitWait caps how long a request waits for a rate limit to reset. If
+	// the limit resets later than that, the rate limit error is returned instead.
+	maxRateLimitWait = 15 * time.Minute
+	// defaultSecondaryWait is how long to wait for a secondary rate limit that does
+	// not say when to retry, as recommended by GitHub.
+	defaultSecondaryWait = time.Minute
+)
+
+// sleep waits for d or until ctx is done, and is replaced in tests.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryTransport is an http.RoundTripper that waits out primary and secondary rate
+// limits, and retries server errors with jittered exponential backoff, so that a run
+// does not stop halfway through a directory.
+type retryTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip sends the request, retrying it as long as the failure is transient.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil {
+			if req.GetBody == nil {
+				return nil, errors.New("cannot retry request with a body that cannot be replayed")
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		logRateLimit(resp)
+
+		delay, retry := retryDelay(resp, attempt)
+		if !retry {
+			return resp, nil
+		}
+		resp.Body.Close()
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryDelay decides whether a response should be retried, and after how long.
+func retryDelay(resp *http.Response, attempt int) (time.Duration, bool) {
+	if attempt >= maxRetries {
+		return 0, false
+	}
+
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		delay := backoff(attempt)
+		slog.Debug("Retrying GitHub request after server error", "url", resp.Request.URL.String(), "status", resp.StatusCode, "delay", delay)
+		return delay, true
+	case http.StatusTooManyRequests:
+		return rateLimitDelay(resp, "secondary", retryAfter(resp))
+	}
+
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	switch err := github.CheckResponse(resp); {
+	case errors.As(err, &rateLimitErr):
+		return rateLimitDelay(resp, "primary", time.Until(rateLimitErr.Rate.Reset.Time)+time.Second)
+	case errors.As(err, &abuseErr):
+		delay := defaultSecondaryWait
+		if abuseErr.RetryAfter != nil {
+			delay = *abuseErr.RetryAfter
+		}
+		return rateLimitDelay(resp, "secondary", delay)
+	default:
+		return 0, false
+	}
+}
+
+// rateLimitDelay returns the delay before retrying a rate limited request, unless it
+// is longer than maxRateLimitWait.
+func rateLimitDelay(resp *http.Response, limit string, delay time.Duration) (time.Duration, bool) {
+	if delay < 0 {
+		delay = 0
+	}
+	if delay > maxRateLimitWait {
+		slog.Warn("GitHub rate limit exceeded, and it resets too late to wait for",
+			"limit", limit,
+			"resetsIn", delay.Round(time.Second),
+			"maxWait", maxRateLimitWait)
+		return 0, false
+	}
+	slog.Warn("GitHub rate limit exceeded, waiting before retrying",
+		"limit", limit,
+		"url", resp.Request.URL.String(),
+		"wait", delay.Round(time.Second))
+	return delay, true
+}
+
+// retryAfter returns the delay from a Retry-After header, or defaultSecondaryWait.
+func retryAfter(resp *http.Response) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultSecondaryWait
+}
+
+// backoff returns the jittered delay before a retry: retryBaseDelay doubled for each
+// earlier retry, plus up to half of that again.
+func backoff(attempt int) time.Duration {
+	delay := retryBaseDelay << attempt
+	return delay + rand.N(delay/2+1)
+}
+
+// logRateLimit reports the remaining quota at debug level.
+func logRateLimit(resp *http.Response) {
+	remaining := resp.Header.Get("X-RateLimit-Remaining")
+	if remaining == "" {
+		return
+	}
+	slog.Debug("GitHub rate limit",
+		"resource", resp.Header.Get("X-RateLimit-Resource"),
+		"remaining", remaining,
+		"limit", resp.Header.Get("X-RateLimit-Limit"),
+		"reset", resp.Header.Get("X-RateLimit-Reset"))
+}
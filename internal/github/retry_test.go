@@ -0,0 +1,174 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// stubSleep records the delays the retry transport waits for, without waiting.
+func stubSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var delays []time.Duration
+	original := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	t.Cleanup(func() { sleep = original })
+	return &delays
+}
+
+func TestRetryTransport(t *testing.T) {
+	resetHosts(t)
+
+	tests := []struct {
+		name string
+		// responses are sent in order, after which requests succeed
+		responses  []func(w http.ResponseWriter)
+		wantStatus int
+		wantCalls  int
+		wantDelays []time.Duration
+	}{
+		{
+			name: "server errors are retried",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+			},
+			wantStatus: http.StatusOK,
+			wantCalls:  3,
+		},
+		{
+			name: "server errors give up after the last retry",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) },
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantCalls:  4,
+		},
+		{
+			name: "secondary rate limit waits for Retry-After",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "30")
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"message": "You have exceeded a secondary rate limit.", "documentation_url": "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits"}`))
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+			wantDelays: []time.Duration{30 * time.Second},
+		},
+		{
+			name: "too many requests waits for Retry-After",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "5")
+					w.WriteHeader(http.StatusTooManyRequests)
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+			wantDelays: []time.Duration{5 * time.Second},
+		},
+		{
+			name: "primary rate limit resetting too late is not waited for",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("X-RateLimit-Limit", "60")
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"message": "API rate limit exceeded"}`))
+				},
+			},
+			wantStatus: http.StatusForbidden,
+			wantCalls:  1,
+			wantDelays: []time.Duration{},
+		},
+		{
+			name: "other client errors are not retried",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) },
+			},
+			wantStatus: http.StatusNotFound,
+			wantCalls:  1,
+			wantDelays: []time.Duration{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delays := stubSleep(t)
+
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls <= len(tt.responses) {
+					tt.responses[calls-1](w)
+					return
+				}
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: &retryTransport{next: http.DefaultTransport}}
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("server received %d requests, want %d", calls, tt.wantCalls)
+			}
+			if len(*delays) != tt.wantCalls-1 {
+				t.Errorf("waited %d times, want %d", len(*delays), tt.wantCalls-1)
+			}
+			if tt.wantDelays != nil {
+				for i, want := range tt.wantDelays {
+					if i < len(*delays) && (*delays)[i] != want {
+						t.Errorf("delay %d = %v, want %v", i, (*delays)[i], want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 3; attempt++ {
+		base := retryBaseDelay << attempt
+		for i := 0; i < 20; i++ {
+			if got := backoff(attempt); got < base || got > base+base/2 {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", attempt, got, base, base+base/2)
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lockfile reads and writes the lockfile of resolved actions, which lets
+// actions be pinned and verified without access to GitHub.
+package lockfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// DefaultPath is where the lockfile is kept, relative to the root of the repository.
+const DefaultPath = ".github/actions.lock"
+
+// formatVersion is the version of the lockfile format written by this version of the tool.
+const formatVersion = 1
+
+const header = "# Resolved versions and commit SHAs of the actions used in this repository.\n" +
+	"# Generated by actions-toolkit lock; do not edit by hand.\n"
+
+// Lockfile records what each ref of each action resolves to.
+type Lockfile struct {
+	FormatVersion int                         `yaml:"lockfile-version"`
+	Actions       map[string]map[string]Entry `yaml:"actions"` // Entries by action name, then by ref
+}
+
+// Entry is the resolution of a ref of an action: the release it was pinned to, and the
+// commit SHA of that release.
+type Entry struct {
+	Version string `yaml:"version"`
+	SHA     string `yaml:"sha"`
+}
+
+// New returns an empty lockfile.
+func New() *Lockfile {
+	return &Lockfile{FormatVersion: formatVersion, Actions: make(map[string]map[string]Entry)}
+}
+
+// Load reads the lockfile at path.
+func Load(path string) (*Lockfile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("lockfile %s does not exist, run the lock command to create it", path)
+		}
+		return nil, err
+	}
+
+	lock := New()
+	decoder := yamlv3.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(lock); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid lockfile %s: %w", path, err)
+	}
+	if lock.FormatVersion > formatVersion {
+		return nil, fmt.Errorf("lockfile %s has version %d, which is newer than this tool supports", path, lock.FormatVersion)
+	}
+	if lock.Actions == nil {
+		lock.Actions = make(map[string]map[string]Entry)
+	}
+	return lock, nil
+}
+
+// Save writes the lockfile to path, creating its directory if needed. Actions and refs
+// are written in lexical order, so that the file only changes when a resolution does.
+func (l *Lockfile) Save(path string) error {
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	encoder := yamlv3.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(l); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Lookup returns the entry for a ref of an action.
+func (l *Lockfile) Lookup(action string, ref string) (Entry, bool) {
+	entry, ok := l.Actions[action][ref]
+	return entry, ok
+}
+
+// Set records the entry for a ref of an action.
+func (l *Lockfile) Set(action string, ref string, entry Entry) {
+	if l.Actions[action] == nil {
+		l.Actions[action] = make(map[string]Entry)
+	}
+	l.Actions[action][ref] = entry
+}
+
+// Tags returns the versions of an action that are recorded as pointing at a commit SHA.
+func (l *Lockfile) Tags(action string, sha string) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, entry := range l.Actions[action] {
+		if entry.SHA == sha && entry.Version != "" && !seen[entry.Version] {
+			seen[entry.Version] = true
+			tags = append(tags, entry.Version)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -0,0 +1,88 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lockfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".github", "actions.lock")
+
+	lock := New()
+	lock.Set("actions/setup-node", "v4", Entry{Version: "v4.4.0", SHA: "49933ea5288caeca8642d1e84afbd3f7d6820020"})
+	lock.Set("actions/checkout", "v4", Entry{Version: "v4.2.2", SHA: "11bd71901bbe5b1630ceea73d27597364c9af683"})
+	lock.Set("actions/checkout", "v4.2.2", Entry{Version: "v4.2.2", SHA: "11bd71901bbe5b1630ceea73d27597364c9af683"})
+	if err := lock.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkout, setupNode := strings.Index(string(content), "actions/checkout"), strings.Index(string(content), "actions/setup-node"); checkout > setupNode {
+		t.Errorf("Save() did not write actions in lexical order:\n%s", content)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, lock) {
+		t.Errorf("Load() = %+v, want %+v", loaded, lock)
+	}
+
+	entry, found := loaded.Lookup("actions/checkout", "v4")
+	if !found || entry.Version != "v4.2.2" {
+		t.Errorf("Lookup() = %+v, %v, want v4.2.2", entry, found)
+	}
+	if _, found := loaded.Lookup("actions/checkout", "v3"); found {
+		t.Error("Lookup() found a ref that was never set")
+	}
+	if tags := loaded.Tags("actions/checkout", "11bd71901bbe5b1630ceea73d27597364c9af683"); !reflect.DeepEqual(tags, []string{"v4.2.2"}) {
+		t.Errorf("Tags() = %v, want [v4.2.2]", tags)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Load(filepath.Join(dir, "missing.lock")); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Load() of a missing file error = %v, want a does not exist error", err)
+	}
+
+	newer := filepath.Join(dir, "newer.lock")
+	if err := os.WriteFile(newer, []byte("lockfile-version: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(newer); err == nil {
+		t.Error("Load() of a newer lockfile version succeeded, want an error")
+	}
+
+	unknown := filepath.Join(dir, "unknown.lock")
+	if err := os.WriteFile(unknown, []byte("packages: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(unknown); err == nil {
+		t.Error("Load() with an unknown field succeeded, want an error")
+	}
+}
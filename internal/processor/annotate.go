@@ -0,0 +1,159 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/aymanbagabas/go-udiff"
+	"github.com/behnh/actions-toolkit/internal/file"
+)
+
+// AnnotateActions adds a version comment to every SHA-pinned action in the given files
+// that does not have one, leaving the SHA itself alone. The version is the most
+// specific version tag pointing at the pinned commit, with the action's tag prefix if
+// it has one. With a lockfile, the tags are taken from the versions recorded in it.
+// Ignored actions are skipped. It returns a result for each SHA-pinned action without
+// a version comment, or for each file that could not be processed.
+func AnnotateActions(files []string, opts Options) []Result {
+	var results []Result
+
+	// Many steps tend to pin the same commits, so each is only looked up once
+	versions := make(map[target]resolution)
+
+	for _, f := range files {
+		// Read the file
+		content, err := file.ReadFile(f)
+		if err != nil {
+			slog.Error("Failed to read file", "file", f, "error", err)
+			results = append(results, fileError(f, err))
+			continue
+		}
+
+		// Parse the file for 'uses' values
+		usesValues, err := file.ParseUses(f, content)
+		if err != nil {
+			slog.Error("Failed to parse file", "file", f, "error", err)
+			results = append(results, fileError(f, err))
+			continue
+		}
+
+		var edits []file.Edit
+		for _, u := range usesValues {
+			if u.IsImage() {
+				continue
+			}
+			actionName, currentVersion, ok := splitUses(u.Value)
+			if !ok || !(len(currentVersion) == 40 && isHexString(currentVersion)) || opts.commentVersion(actionName, u.Comment) != "" {
+				continue
+			}
+			result := newResult(u)
+
+			if opts.ignored(actionName) {
+				slog.Debug("Skipping ignored action", "action", actionName, "file", f)
+				result.Status = StatusSkipped
+				results = append(results, result)
+				continue
+			}
+
+			key := target{action: actionName, ref: currentVersion}
+			if _, found := versions[key]; !found {
+				version, err := commitVersion(opts, actionName, currentVersion)
+				versions[key] = resolution{version: version, sha: currentVersion, err: err}
+			}
+			resolution := versions[key]
+			if resolution.err != nil {
+				slog.Error("Failed to look up tags for pinned SHA", "action", actionName, "sha", currentVersion, "file", f, "error", resolution.err)
+				results = append(results, result.fail(resolution.err))
+				continue
+			}
+			if resolution.version == "" {
+				slog.Warn("No version tag points at pinned SHA",
+					"action", actionName,
+					"sha", currentVersion,
+					"file", f,
+					"line", u.Line,
+					"hint", "Run verify to check where the SHA comes from")
+				result.Status = StatusSkipped
+				results = append(results, result)
+				continue
+			}
+
+			// A comment is always added, since adding one is the point of annotating
+			style := opts.CommentStyle
+			if style == CommentNone {
+				style = CommentVersion
+			}
+			edits = append(edits, rewriteUses(content, u, u.Value, resolution.version, opts.tagPrefix(actionName), style)...)
+			result.Status = StatusChanged
+			result.NewVersion = resolution.version
+			results = append(results, result)
+
+			slog.Debug("Annotated action in memory",
+				"action", actionName,
+				"sha", currentVersion,
+				"version", resolution.version,
+				"file", f,
+				"line", u.Line)
+		}
+
+		if len(edits) == 0 {
+			slog.Info("No changes to file", "file", f)
+			continue
+		}
+
+		newContent, err := file.ApplyEdits(content, edits)
+		if err != nil {
+			slog.Error("Failed to apply changes", "file", f, "error", err)
+			results = append(results, fileError(f, err))
+			continue
+		}
+
+		// Write changes to file if needed
+		if opts.Write {
+			if err := os.WriteFile(f, newContent, 0644); err != nil {
+				slog.Error("Failed to write file", "file", f, "error", err)
+				results = append(results, fileError(f, err))
+				continue
+			}
+			slog.Info("Successfully updated file with version comments", "file", f)
+		} else {
+			slog.Info(fmt.Sprintf("Dry run - not updating file. Would have applied:\n%s\n", udiff.Unified(f, f, string(content), string(newContent))))
+		}
+	}
+
+	return results
+}
+
+// commitVersion returns the most specific version tag of an action that points at a
+// commit, with the action's tag prefix if it has one, or an empty string if no version
+// tag points at it.
+func commitVersion(opts Options, actionName string, sha string) (string, error) {
+	var tags []string
+	if opts.Lock != nil {
+		tags = opts.Lock.Tags(actionName, sha)
+	} else {
+		var err error
+		if tags, err = opts.resolver().TagsForCommit(actionName, sha); err != nil {
+			return "", err
+		}
+	}
+
+	return mostSpecificTag(tags, "", opts.tagPrefix(actionName)), nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/behnh/actions-toolkit/internal/processor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotateActions(t *testing.T) {
+	workflow := `jobs:
+  build:
+    steps:
+      - uses: actions/cache/save@5a3ec84eff668545956fd18022155c47e93e2684
+      - uses: actions/cache@1bd1e32a3bdc45362d1e726936510720a7c30a57 # restore the cache
+      - uses: actions/setup-node@cdca7365b2dadb8aad0a33bc7601856ffabcc48e # v4.3.0
+      - uses: actions/setup-node@v4
+      - uses: octo-org/tags-only@0000000000000000000000000000000000000001
+`
+	path := filepath.Join(t.TempDir(), "workflow.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(workflow), 0644))
+
+	// A dry run does not change the file
+	opts := processor.Options{Resolver: fixtureResolver(t)}
+	results := processor.AnnotateActions([]string{path}, opts)
+	assert.Equal(t, processor.Summary{Total: 3, Changed: 2}, processor.Summarize(results))
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, workflow, string(content))
+
+	opts.Write = true
+	results = processor.AnnotateActions([]string{path}, opts)
+	assert.Len(t, results, 3)
+	assert.Equal(t, "v4.2.3", results[0].NewVersion)
+	assert.Equal(t, "v4.2.0", results[1].NewVersion)
+	assert.Equal(t, processor.StatusSkipped, results[2].Status)
+
+	// The SHAs are left alone, and only the missing versions are added
+	content, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, `jobs:
+  build:
+    steps:
+      - uses: actions/cache/save@5a3ec84eff668545956fd18022155c47e93e2684 # v4.2.3
+      - uses: actions/cache@1bd1e32a3bdc45362d1e726936510720a7c30a57 # v4.2.0 restore the cache
+      - uses: actions/setup-node@cdca7365b2dadb8aad0a33bc7601856ffabcc48e # v4.3.0
+      - uses: actions/setup-node@v4
+      - uses: octo-org/tags-only@0000000000000000000000000000000000000001
+`, string(content))
+
+	// Annotated actions are not annotated again
+	results = processor.AnnotateActions([]string{path}, opts)
+	assert.Equal(t, processor.Summary{Total: 1}, processor.Summarize(results))
+}
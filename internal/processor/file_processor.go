@@ -18,41 +18,75 @@ package processor
 
 import (
 	"fmt"
-	"github.com/behnh/actions-toolkit/internal/file"
-	"github.com/behnh/actions-toolkit/internal/github"
 	"log/slog"
 	"os"
+	"sort"
 	"strings"
+
+	"github.com/behnh/actions-toolkit/internal/file"
 )
 
-func FindActionsInFile(filePath string) []string {
-	// Read the file
-	content, err := file.ReadFile(filePath)
-	if err != nil {
-		slog.Error("Failed to read file", "file", filePath, "error", err)
-		return nil
+// workflowFile is a file with the uses values parsed from it, or the error that
+// prevented reading or parsing it.
+type workflowFile struct {
+	path    string
+	content []byte
+	uses    []file.Uses
+	err     error
+}
+
+// readWorkflowFiles reads and parses each of the given files, in order.
+func readWorkflowFiles(files []string) []workflowFile {
+	workflows := make([]workflowFile, len(files))
+	for i, f := range files {
+		workflows[i].path = f
+
+		// Read the file
+		content, err := file.ReadFile(f)
+		if err != nil {
+			slog.Error("Failed to read file", "file", f, "error", err)
+			workflows[i].err = err
+			continue
+		}
+		workflows[i].content = content
+
+		// Parse the file for 'uses' values
+		workflows[i].uses, err = file.ParseUses(f, content)
+		if err != nil {
+			slog.Error("Failed to parse file", "file", f, "error", err)
+			workflows[i].err = err
+		}
 	}
+	return workflows
+}
 
-	// Parse the file for 'uses' values
-	usesValues, err := file.ParseYAMLForUses(content)
-	if err != nil {
-		slog.Error("Failed to parse file", "file", filePath, "error", err)
+func FindActionsInFile(filePath string) []string {
+	workflow := readWorkflowFiles([]string{filePath})[0]
+	if workflow.err != nil {
 		return nil
 	}
+	return actionsInWorkflow(workflow)
+}
 
+// actionsInWorkflow returns the actions used in a parsed file, other than at main.
+func actionsInWorkflow(workflow workflowFile) []string {
+	filePath, usesValues := workflow.path, workflow.uses
 	var actions []string
 
 	// Find the specified action in the uses values
-	for _, uses := range usesValues {
-		parts := strings.Split(uses, "@")
-		slog.Debug(fmt.Sprintf("Found uses value: %s", uses))
-		if len(parts) != 2 {
+	for _, u := range usesValues {
+		slog.Debug(fmt.Sprintf("Found uses value: %s", u.Value), "file", filePath, "line", u.Line)
+		if u.IsImage() {
+			continue
+		}
+		actionName, version, ok := splitUses(u.Value)
+		if !ok {
 			continue
 		}
 
 		// If the action version is not "main", add it to the list
-		if parts[1] != "main" {
-			actions = append(actions, strings.TrimSpace(parts[0]))
+		if version != "main" {
+			actions = append(actions, actionName)
 		}
 	}
 
@@ -60,169 +94,186 @@ func FindActionsInFile(filePath string) []string {
 }
 
 func FindActionsInFiles(files []string) []string {
+	return uniqueActions(readWorkflowFiles(files))
+}
+
+// uniqueActions returns the actions used in the parsed files, other than at main, in
+// lexical order.
+func uniqueActions(workflows []workflowFile) []string {
 	// Use a map to store unique actions
 	actionMap := make(map[string]bool)
 
-	for _, f := range files {
-		for _, action := range FindActionsInFile(f) {
+	for _, workflow := range workflows {
+		if workflow.err != nil {
+			continue
+		}
+		for _, action := range actionsInWorkflow(workflow) {
 			actionMap[action] = true
 		}
 	}
 
-	// Convert map keys back to slice
+	// Convert map keys back to slice, in a stable order
 	actions := make([]string, 0, len(actionMap))
 	for action := range actionMap {
 		actions = append(actions, action)
 	}
+	sort.Strings(actions)
 
 	return actions
 }
 
-func UpdateAction(filePath, actionName, token string, write bool) {
+// UpdateAction updates every use of an action in a file to the newest release allowed
+// by its update strategy, or to the version configured for it. It returns a result for
+// each use of the action, or for the file if it could not be processed.
+func UpdateAction(filePath, actionName string, opts Options) []Result {
+	var results []Result
+
 	// Read the file
 	content, err := file.ReadFile(filePath)
 	if err != nil {
 		slog.Error("Failed to read file", "file", filePath, "error", err)
-		return
+		return []Result{fileError(filePath, err)}
 	}
 
-	contentModified := false
-	contentStr := string(content)
+	var edits []file.Edit
 
 	// Parse the file for 'uses' values
-	usesValues, err := file.ParseYAMLForUses(content)
+	usesValues, err := file.ParseUses(filePath, content)
 	if err != nil {
 		slog.Error("Failed to parse file", "file", filePath, "error", err)
-		return
+		return []Result{fileError(filePath, err)}
+	}
+
+	// Prefetch the latest release of the action, which each of its uses resolves below
+	var targets []target
+	for _, u := range usesValues {
+		if name, currentVersion, ok := splitUses(u.Value); ok && !u.IsImage() && name == actionName && currentVersion != "main" {
+			targets = append(targets, newTarget(u, name, currentVersion))
+		}
 	}
+	prefetchLatestReleases(opts, targets)
 
 	// Find the specified action in the uses values
-	for _, uses := range usesValues {
+	for _, u := range usesValues {
 		// Check if this uses value matches the action we're looking for
-		if strings.HasPrefix(uses, actionName+"@") {
-			parts := strings.Split(uses, "@")
-			slog.Debug(fmt.Sprintf("Found uses value: %s", uses))
-			if len(parts) != 2 {
-				continue
-			}
-
-			currentVersion := parts[1]
-			slog.Info("Found action", "action", actionName, "version", currentVersion, "file", filePath)
-
-			// Skip if version is "main"
-			if currentVersion == "main" {
-				slog.Info("Skipping action with 'main' version", "action", actionName, "file", filePath)
-				continue
-			}
-
-			// Get the latest release with SHA
-			latestRelease, latestSHA, err := github.GetLatestReleaseWithSHA(token, actionName, currentVersion)
-			if err != nil {
-				slog.Error("Failed to get latest release", "action", actionName, "error", err)
-				continue
-			}
-
-			if latestRelease == "" {
-				slog.Info("No release found for action", "action", actionName)
-				continue
-			}
-
-			// Compare versions
-			if currentVersion != latestRelease || (len(currentVersion) == 40 && isHexString(currentVersion) && latestSHA != "" && currentVersion != latestSHA) {
-				slog.Info("Update available",
-					"action", actionName,
-					"current", currentVersion,
-					"latest", latestRelease,
-					"latestSHA", latestSHA,
-					"file", filePath)
-
-				// Update the file if write is true
-				if write {
-					var newContent string
-
-					// Check if the current version is an SHA (40 hex characters)
-					isSHA := len(currentVersion) == 40 && isHexString(currentVersion)
-
-					if isSHA {
-						// Look for the line with this SHA in the file
-						lines := strings.Split(contentStr, "\n")
-						newLines := make([]string, len(lines))
-
-						for i, line := range lines {
-							if strings.Contains(line, actionName+"@"+currentVersion) {
-								// Replace the SHA with the latest SHA
-								updatedAction := fmt.Sprintf("%s@%s", actionName, latestSHA)
-								updatedLine := strings.Replace(line, actionName+"@"+currentVersion, updatedAction, 1)
-
-								slog.Debug("Updated line", "originalLine", line, "updatedLine", updatedLine)
-
-								// Update the comment with the new version using the shared function
-								newLines[i] = updateVersionComment(updatedLine, latestRelease)
-								slog.Debug("Comment updated", "before", updatedLine, "after", newLines[i])
-							} else {
-								newLines[i] = line
-							}
-						}
-
-						newContent = strings.Join(newLines, "\n")
-						contentStr = newContent
-						contentModified = true
-					} else {
-						// Check if the current version is a major version constraint
-						if isMajorVersionConstraint(currentVersion) {
-							// Extract the major version from the latest release
-							latestMajorVersion := extractMajorVersion(latestRelease)
-							// Replace the version in the file content, preserving the major version constraint
-							newContent = strings.ReplaceAll(contentStr,
-								actionName+"@"+currentVersion,
-								actionName+"@"+latestMajorVersion)
-
-							slog.Debug("Updating major version constraint",
-								"action", actionName,
-								"from", currentVersion,
-								"to", latestMajorVersion)
-						} else {
-							// Replace the version in the file content with the full version
-							newContent = strings.ReplaceAll(contentStr,
-								actionName+"@"+currentVersion,
-								actionName+"@"+latestRelease)
-
-							slog.Debug("Updating full version",
-								"action", actionName,
-								"from", currentVersion,
-								"to", latestRelease)
-						}
-						contentStr = newContent
-						contentModified = true
-					}
-
-					slog.Info("Updated action in memory",
-						"action", actionName,
-						"from", currentVersion,
-						"to", latestRelease,
-						"file", filePath)
-				} else {
-					slog.Info("Dry run - not updating file",
-						"action", actionName,
-						"file", filePath,
-						"hint", "Use --write/-w to update files")
-				}
-			} else {
-				slog.Info("Action is already up to date",
-					"action", actionName,
-					"version", currentVersion,
-					"file", filePath)
-			}
+		if u.IsImage() {
+			continue
+		}
+		name, currentVersion, ok := splitUses(u.Value)
+		if !ok || name != actionName {
+			continue
+		}
+		result := newResult(u)
+		slog.Debug(fmt.Sprintf("Found uses value: %s", u.Value), "file", filePath, "line", u.Line)
+		slog.Info("Found action", "action", actionName, "version", currentVersion, "file", filePath, "line", u.Line)
+
+		// Skip if version is "main"
+		if currentVersion == "main" {
+			slog.Info("Skipping action with 'main' version", "action", actionName, "file", filePath)
+			result.Status = StatusSkipped
+			results = append(results, result)
+			continue
+		}
+
+		// Get the release allowed by the update strategy, or the configured version, with SHA
+		latestRelease, latestSHA, err := resolveTarget(opts, actionName, currentVersion, u.Comment)
+		if err != nil {
+			slog.Error("Failed to get latest release", "action", actionName, "error", err)
+			results = append(results, result.fail(err))
+			continue
+		}
+
+		if latestRelease == "" {
+			slog.Info("No release found for action", "action", actionName)
+			result.Status = StatusSkipped
+			results = append(results, result)
+			continue
+		}
+
+		// Check if the current version is an SHA (40 hex characters)
+		isSHA := len(currentVersion) == 40 && isHexString(currentVersion)
+
+		// Versions are compared without the tag prefix of actions in monorepos (e.g., sub-v1.2.0)
+		prefix := opts.tagPrefix(actionName)
+		current, latest := strings.TrimPrefix(currentVersion, prefix), strings.TrimPrefix(latestRelease, prefix)
+
+		// Only SHA refs have a version comment to update
+		var newRef, comment string
+		if isSHA {
+			// Replace the SHA with the latest SHA and update the comment with the new version
+			newRef = latestSHA
+			comment = latestRelease
+		} else if isMajorVersionConstraint(current) && !isPrerelease(latest) {
+			// Extract the major version from the latest release, preserving the major version constraint.
+			// Major version tags do not move to prereleases, so those are used in full.
+			newRef = prefix + extractMajorVersion(latest)
+		} else {
+			// Replace the version with the full version
+			newRef = latestRelease
+		}
+
+		// Compare versions
+		if newRef == "" || newRef == currentVersion {
+			slog.Info("Action is already up to date",
+				"action", actionName,
+				"version", currentVersion,
+				"file", filePath)
+			result.Status = StatusUnchanged
+			results = append(results, result)
+			continue
 		}
+
+		slog.Info("Update available",
+			"action", actionName,
+			"current", currentVersion,
+			"latest", latestRelease,
+			"latestSHA", latestSHA,
+			"file", filePath,
+			"line", u.Line)
+
+		result.Status = StatusChanged
+		result.NewRef = newRef
+		result.NewVersion = latestRelease
+		results = append(results, result)
+
+		// Only update the file if write is true
+		if !opts.Write {
+			slog.Info("Dry run - not updating file",
+				"action", actionName,
+				"file", filePath,
+				"hint", "Use --write/-w to update files")
+			continue
+		}
+
+		edits = append(edits, rewriteUses(content, u, actionName+"@"+result.NewRef, comment, prefix, opts.CommentStyle)...)
+
+		slog.Debug("Updating version",
+			"action", actionName,
+			"from", currentVersion,
+			"to", result.NewRef)
+
+		slog.Info("Updated action in memory",
+			"action", actionName,
+			"from", currentVersion,
+			"to", latestRelease,
+			"file", filePath,
+			"line", u.Line)
 	}
 
 	// Write the updated content back to the file if it was modified and write mode is enabled
-	if contentModified && write {
-		err = os.WriteFile(filePath, []byte(contentStr), 0644)
+	if len(edits) > 0 && opts.Write {
+		newContent, err := file.ApplyEdits(content, edits)
+		if err != nil {
+			slog.Error("Failed to apply changes", "file", filePath, "error", err)
+			return append(results, fileError(filePath, err))
+		}
+		err = os.WriteFile(filePath, newContent, 0644)
 		if err != nil {
 			slog.Error("Failed to write file", "file", filePath, "error", err)
-			return
+			return append(results, fileError(filePath, err))
 		}
 		slog.Info("Successfully wrote file with all updates", "file", filePath)
 	}
+
+	return results
 }
@@ -0,0 +1,73 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/behnh/actions-toolkit/internal/file"
+	"github.com/behnh/actions-toolkit/internal/registry"
+)
+
+// pinImage returns the edits that pin a container image (a docker:// step, a job
+// container or a service container) to the digest its tag currently points to,
+// e.g. alpine:3.19 becomes alpine:3.19@sha256:... # 3.19, along with result, the
+// result for u, completed with the outcome.
+func pinImage(content []byte, u file.Uses, result Result, resolver Resolver, style CommentStyle) ([]file.Edit, Result) {
+	image := strings.TrimPrefix(u.Value, file.DockerPrefix)
+	prefix := u.Value[:len(u.Value)-len(image)]
+
+	// Images chosen by an expression can only be resolved at runtime
+	if strings.Contains(image, "${{") {
+		slog.Debug("Skipping image set by an expression", "image", image, "file", u.File, "line", u.Line)
+		result.Status = StatusSkipped
+		return nil, result
+	}
+
+	ref, err := registry.ParseReference(image)
+	if err != nil {
+		return nil, result.fail(err)
+	}
+
+	if ref.Digest != "" {
+		slog.Info("Image is already pinned to a digest", "image", image, "file", u.File)
+		result.Status = StatusUnchanged
+		return nil, result
+	}
+
+	digest, err := resolver.ImageDigest(image)
+	if err != nil {
+		return nil, result.fail(err)
+	}
+
+	tag := ref.Tag
+	if tag == "" {
+		tag = "latest"
+	}
+
+	slog.Debug("Pinning image",
+		"image", image,
+		"digest", digest,
+		"file", u.File,
+		"line", u.Line)
+
+	result.Status = StatusChanged
+	result.NewRef = strings.TrimPrefix(ref.Tag+"@"+digest, "@")
+	result.NewVersion = tag
+	return rewriteUses(content, u, prefix+image+"@"+digest, tag, "", style), result
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/behnh/actions-toolkit/internal/processor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinAllActionsImages(t *testing.T) {
+	// A local stand-in for an OCI registry
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/tools/lint/manifests/3.19":
+			w.Header().Set("Docker-Content-Digest", "sha256:aaaa")
+		case "/v2/db/postgres/manifests/16":
+			w.Header().Set("Docker-Content-Digest", "sha256:bbbb")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	workflow := `jobs:
+  test:
+    runs-on: ubuntu-latest
+    container: HOST/tools/lint:3.19
+    services:
+      db:
+        image: "HOST/db/postgres:16"
+      cache:
+        image: HOST/db/redis@sha256:cccc
+    steps:
+      - uses: docker://HOST/tools/lint:3.19
+      - uses: docker://${{ matrix.image }}
+`
+	tempFile := filepath.Join(t.TempDir(), "workflow.yaml")
+	err := os.WriteFile(tempFile, []byte(strings.ReplaceAll(workflow, "HOST", host)), 0644)
+	assert.NoError(t, err)
+
+	results := processor.PinAllActions([]string{tempFile}, processor.Options{Token: "mock-token", Write: true})
+	assert.Equal(t, processor.Summary{Total: 5, Changed: 3}, processor.Summarize(results))
+
+	statuses := make([]processor.Status, len(results))
+	for i, r := range results {
+		statuses[i] = r.Status
+	}
+	assert.Equal(t, []processor.Status{
+		processor.StatusChanged,
+		processor.StatusChanged,
+		processor.StatusUnchanged,
+		processor.StatusChanged,
+		processor.StatusSkipped,
+	}, statuses)
+	assert.Equal(t, "16", results[1].OldRef)
+	assert.Equal(t, "16@sha256:bbbb", results[1].NewRef)
+	assert.Equal(t, 7, results[1].Line)
+
+	content, err := os.ReadFile(tempFile)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.ReplaceAll(`jobs:
+  test:
+    runs-on: ubuntu-latest
+    container: HOST/tools/lint:3.19@sha256:aaaa # 3.19
+    services:
+      db:
+        image: "HOST/db/postgres:16@sha256:bbbb" # 16
+      cache:
+        image: HOST/db/redis@sha256:cccc
+    steps:
+      - uses: docker://HOST/tools/lint:3.19@sha256:aaaa # 3.19
+      - uses: docker://${{ matrix.image }}
+`, "HOST", host), string(content))
+}
@@ -0,0 +1,160 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/behnh/actions-toolkit/internal/file"
+	"github.com/behnh/actions-toolkit/internal/lockfile"
+)
+
+// LockActions resolves every action in the given files the way PinAllActions would,
+// and records the resolutions in a new lockfile, so that the files can later be pinned
+// and verified without access to GitHub. The version that each ref resolves to is
+// recorded as well. The version comment of each SHA-pinned action is resolved on its
+// own, so a wrong comment is not recorded as pointing at the pinned SHA. Ignored
+// actions are left out. It returns the lockfile and a result for each action, or for
+// each file that could not be processed.
+func LockActions(files []string, opts Options) (*lockfile.Lockfile, []Result) {
+	lock := lockfile.New()
+	opts.Lock = nil
+
+	var results []Result
+	for _, f := range files {
+		// Read the file
+		content, err := file.ReadFile(f)
+		if err != nil {
+			slog.Error("Failed to read file", "file", f, "error", err)
+			results = append(results, fileError(f, err))
+			continue
+		}
+
+		// Parse the file for 'uses' values
+		usesValues, err := file.ParseUses(f, content)
+		if err != nil {
+			slog.Error("Failed to parse file", "file", f, "error", err)
+			results = append(results, fileError(f, err))
+			continue
+		}
+
+		for _, u := range usesValues {
+			if u.IsImage() {
+				continue
+			}
+			actionName, currentVersion, ok := splitUses(u.Value)
+			if !ok || opts.ignored(actionName) {
+				continue
+			}
+			result := newResult(u)
+
+			version, sha, err := pinTarget(opts, actionName, currentVersion, u.Comment)
+			if err != nil {
+				slog.Error("Failed to resolve action", "action", actionName, "ref", currentVersion, "error", err)
+				results = append(results, result.fail(err))
+				continue
+			}
+			if version == "" || sha == "" {
+				slog.Info("No release found for action, leaving it out of the lockfile", "action", actionName, "ref", currentVersion)
+				result.Status = StatusSkipped
+				results = append(results, result)
+				continue
+			}
+
+			// The version comment of a SHA-pinned action may be wrong, so the version is
+			// resolved on its own rather than taken from the SHA. Verifying the action looks
+			// it up.
+			isSHA := len(currentVersion) == 40 && isHexString(currentVersion)
+			if commentVersion := opts.commentVersion(actionName, u.Comment); isSHA && commentVersion != "" {
+				if _, found := lock.Lookup(actionName, commentVersion); !found {
+					commentSHA, err := opts.resolver().TagSHA(actionName, commentVersion)
+					if err != nil {
+						slog.Error("Failed to resolve version comment", "action", actionName, "version", commentVersion, "error", err)
+						results = append(results, result.fail(err))
+						continue
+					}
+					if commentSHA != "" {
+						lock.Set(actionName, commentVersion, lockfile.Entry{Version: commentVersion, SHA: commentSHA})
+					}
+				}
+			}
+
+			entry := lockfile.Entry{Version: version, SHA: sha}
+			if isSHA && sha == currentVersion {
+				// The SHA stays where it is, and only keeps the version of its comment if
+				// that version points at it
+				if locked, _ := lock.Lookup(actionName, version); locked.SHA != sha {
+					slog.Warn("Version comment does not match the pinned SHA", "action", actionName, "sha", sha, "version", version)
+					entry.Version = ""
+				}
+			} else {
+				lock.Set(actionName, version, entry)
+			}
+			lock.Set(actionName, currentVersion, entry)
+
+			slog.Debug("Locked action", "action", actionName, "ref", currentVersion, "version", version, "sha", sha)
+			result.Status = StatusOK
+			result.NewRef = sha
+			result.NewVersion = entry.Version
+			results = append(results, result)
+		}
+	}
+
+	return lock, results
+}
+
+// lockedTarget returns the version and commit SHA that a ref of an action resolves to
+// according to the lockfile.
+func lockedTarget(lock *lockfile.Lockfile, actionName string, ref string) (string, string, error) {
+	entry, found := lock.Lookup(actionName, ref)
+	if !found {
+		return "", "", notLockedError(actionName, ref)
+	}
+	return entry.Version, entry.SHA, nil
+}
+
+// verifyLocked verifies a SHA-pinned action against the lockfile. Without access to
+// GitHub, a SHA that is not in the lockfile cannot be told apart from a fork commit,
+// so it is reported as an error rather than a finding.
+func verifyLocked(lock *lockfile.Lockfile, r Result, actionName string, sha string) Result {
+	r.Tags = lock.Tags(actionName, sha)
+
+	if r.Version != "" {
+		entry, found := lock.Lookup(actionName, r.Version)
+		if !found {
+			return r.fail(notLockedError(actionName, r.Version))
+		}
+		r.ExpectedRef = entry.SHA
+		if entry.SHA == sha {
+			r.Status = StatusOK
+		} else {
+			r.Status = StatusMismatch
+		}
+		return r
+	}
+
+	if len(r.Tags) == 0 {
+		return r.fail(notLockedError(actionName, sha))
+	}
+	r.Status = StatusOK
+	return r
+}
+
+func notLockedError(actionName string, ref string) error {
+	return fmt.Errorf("%s@%s is not in the lockfile, run the lock command to add it", actionName, ref)
+}
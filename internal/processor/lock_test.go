@@ -0,0 +1,106 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/behnh/actions-toolkit/internal/lockfile"
+	"github.com/behnh/actions-toolkit/internal/processor"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	lockedSHA = "11bd71901bbe5b1630ceea73d27597364c9af683"
+	olderSHA  = "692973e3d937129bcbf40652eb9f2f61becf3332"
+)
+
+func testLockfile() *lockfile.Lockfile {
+	lock := lockfile.New()
+	lock.Set("actions/checkout", "v4", lockfile.Entry{Version: "v4.2.2", SHA: lockedSHA})
+	lock.Set("actions/checkout", "v4.2.2", lockfile.Entry{Version: "v4.2.2", SHA: lockedSHA})
+	lock.Set("actions/checkout", "v4.1.7", lockfile.Entry{Version: "v4.1.7", SHA: olderSHA})
+	return lock
+}
+
+func TestPinAllActionsOffline(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "workflow.yml")
+	content := `jobs:
+  build:
+    runs-on: ubuntu-latest
+    container: node:20
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-node@v4
+`
+	assert.NoError(t, os.WriteFile(testFile, []byte(content), 0644))
+
+	results := processor.PinAllActions([]string{testFile}, processor.Options{Write: true, Lock: testLockfile()})
+
+	byAction := make(map[string]processor.Result)
+	for _, r := range results {
+		byAction[r.Action] = r
+	}
+	assert.Equal(t, processor.StatusChanged, byAction["actions/checkout"].Status)
+	assert.Equal(t, lockedSHA, byAction["actions/checkout"].NewRef)
+	assert.Equal(t, "v4.2.2", byAction["actions/checkout"].NewVersion)
+	assert.Equal(t, processor.StatusError, byAction["actions/setup-node"].Status)
+	assert.Contains(t, byAction["actions/setup-node"].Error, "not in the lockfile")
+	assert.Equal(t, processor.StatusSkipped, byAction["node"].Status)
+
+	written, err := os.ReadFile(testFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(written), "uses: actions/checkout@"+lockedSHA+" # v4.2.2")
+	assert.Contains(t, string(written), "uses: actions/setup-node@v4\n")
+}
+
+func TestVerifyActionsOffline(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "workflow.yml")
+	content := `jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@` + lockedSHA + ` # v4.2.2
+      - uses: actions/checkout@` + olderSHA + ` # v4.2.2
+      - uses: actions/checkout@` + olderSHA + `
+      - uses: actions/checkout@` + lockedSHA + ` # v4.0.0
+      - uses: actions/cache@` + lockedSHA + `
+`
+	assert.NoError(t, os.WriteFile(testFile, []byte(content), 0644))
+
+	results := processor.VerifyActions([]string{testFile}, processor.Options{Lock: testLockfile()})
+	if assert.Len(t, results, 5) {
+		assert.Equal(t, processor.StatusOK, results[0].Status)
+		assert.Equal(t, []string{"v4.2.2"}, results[0].Tags)
+
+		assert.Equal(t, processor.StatusMismatch, results[1].Status)
+		assert.Equal(t, lockedSHA, results[1].ExpectedRef)
+		assert.Equal(t, []string{"v4.1.7"}, results[1].Tags)
+
+		assert.Equal(t, processor.StatusOK, results[2].Status)
+		assert.Equal(t, []string{"v4.1.7"}, results[2].Tags)
+
+		assert.Equal(t, processor.StatusError, results[3].Status)
+		assert.Contains(t, results[3].Error, "actions/checkout@v4.0.0 is not in the lockfile")
+
+		assert.Equal(t, processor.StatusError, results[4].Status)
+	}
+}
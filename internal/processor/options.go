@@ -0,0 +1,303 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/behnh/actions-toolkit/internal/lockfile"
+	"github.com/behnh/actions-toolkit/internal/semver"
+)
+
+// CommentStyle controls the version comment added after a newly pinned value.
+// Existing comments keep their format and only have their version updated.
+type CommentStyle string
+
+const (
+	// CommentVersion adds the version on its own (e.g., # v4.2.2)
+	CommentVersion CommentStyle = "version"
+	// CommentPin adds the version with a pin@ prefix (e.g., # pin@v4.2.2)
+	CommentPin CommentStyle = "pin"
+	// CommentNone does not add a comment
+	CommentNone CommentStyle = "none"
+)
+
+// ParseCommentStyle parses a comment style, defaulting to CommentVersion when empty.
+func ParseCommentStyle(s string) (CommentStyle, error) {
+	switch style := CommentStyle(s); style {
+	case "":
+		return CommentVersion, nil
+	case CommentVersion, CommentPin, CommentNone:
+		return style, nil
+	default:
+		return "", fmt.Errorf("unsupported comment style %q, expected version, pin or none", s)
+	}
+}
+
+// Options controls how actions are resolved and how files are changed.
+type Options struct {
+	Token           string              // GitHub token to use for authentication
+	Write           bool                // Write changes to files instead of a dry run
+	Ignore          []string            // Actions and images to leave alone, as names or path.Match patterns
+	Versions        map[string]string   // Version to use instead of the latest release, by action
+	TagPrefixes     map[string]string   // Prefix of the release tags of actions in monorepos, by action
+	Strategy        Strategy            // How far actions may move from their current version
+	Strategies      map[string]Strategy // Strategy to use instead of Strategy, by action
+	MinAge          time.Duration       // Minimum time since a release was published before it is adopted
+	AllowPrerelease bool                // Adopt prereleases, rather than only newer prereleases of a current prerelease
+	UpdatePinned    bool                // Move SHA-pinned actions to newer releases when pinning, rather than leaving them alone
+	CommentStyle    CommentStyle        // Style of newly added version comments
+	Lock            *lockfile.Lockfile  // Resolve actions from this lockfile only, without contacting GitHub
+	Concurrency     int                 // Number of actions to resolve at the same time, DefaultConcurrency if zero
+	Resolver        Resolver            // Resolves actions and images, a NetworkResolver with Token if nil
+}
+
+// resolver returns the resolver to look up actions and images with.
+func (o Options) resolver() Resolver {
+	if o.Resolver == nil {
+		return NetworkResolver{Token: o.Token}
+	}
+	return o.Resolver
+}
+
+// ignored reports whether an action or image matches one of the ignore patterns.
+// Patterns for an owner/repo also match actions in subpaths of that repository.
+func (o Options) ignored(name string) bool {
+	name = strings.TrimPrefix(name, "docker://")
+	base := name
+	if parts := strings.SplitN(name, "/", 3); len(parts) == 3 {
+		base = parts[0] + "/" + parts[1]
+	}
+
+	for _, pattern := range o.Ignore {
+		pattern = strings.TrimPrefix(pattern, "docker://")
+		for _, candidate := range []string{name, base} {
+			if matched, _ := path.Match(pattern, candidate); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// version returns the version configured for an action, falling back to the version
+// configured for its owner/repo, or an empty string if none is configured.
+func (o Options) version(actionName string) string {
+	if version, ok := o.Versions[actionName]; ok {
+		return version
+	}
+	if parts := strings.SplitN(actionName, "/", 3); len(parts) == 3 {
+		return o.Versions[parts[0]+"/"+parts[1]]
+	}
+	return ""
+}
+
+// tagPrefix returns the prefix of the release tags of an action (e.g., sub- for tags
+// such as sub-v1.2.0), falling back to the prefix configured for its owner/repo, or an
+// empty string if its tags are plain versions.
+func (o Options) tagPrefix(actionName string) string {
+	if prefix, ok := o.TagPrefixes[actionName]; ok {
+		return prefix
+	}
+	if parts := strings.SplitN(actionName, "/", 3); len(parts) == 3 {
+		return o.TagPrefixes[parts[0]+"/"+parts[1]]
+	}
+	return ""
+}
+
+// commentVersion returns the version recorded in the comment of an action, taking the
+// action's tag prefix into account.
+func (o Options) commentVersion(actionName string, comment string) string {
+	return extractCommentVersion(comment, o.tagPrefix(actionName))
+}
+
+// strategy returns the update strategy for an action, falling back to the strategy
+// for its owner/repo and then to the default strategy.
+func (o Options) strategy(actionName string) Strategy {
+	if strategy, ok := o.Strategies[actionName]; ok {
+		return strategy
+	}
+	if parts := strings.SplitN(actionName, "/", 3); len(parts) == 3 {
+		if strategy, ok := o.Strategies[parts[0]+"/"+parts[1]]; ok {
+			return strategy
+		}
+	}
+	if o.Strategy == "" {
+		return StrategyMajor
+	}
+	return o.Strategy
+}
+
+// resolveTarget returns the version and commit SHA that an action should be pinned or
+// updated to: the version configured for it, or otherwise the newest release its
+// update strategy allows that is at least MinAge old. Prereleases are only considered
+// as described by selectVersion. For SHA refs, the current version is taken from the
+// version comment. Actions with a tag prefix only consider the releases with that
+// prefix. With a lockfile, the resolution recorded in it is used instead. An empty
+// version means there is nothing to move to.
+func resolveTarget(opts Options, actionName string, currentVersion string, comment string) (string, string, error) {
+	if opts.Lock != nil {
+		return lockedTarget(opts.Lock, actionName, currentVersion)
+	}
+
+	if version := opts.version(actionName); version != "" {
+		sha, err := opts.resolver().TagSHA(actionName, version)
+		if err != nil {
+			return "", "", err
+		}
+		if sha == "" {
+			return "", "", fmt.Errorf("configured version %s of %s does not exist", version, actionName)
+		}
+		return version, sha, nil
+	}
+
+	strategy := opts.strategy(actionName)
+	prefix := opts.tagPrefix(actionName)
+	pinnedVersion := currentVersion
+	if len(currentVersion) == 40 && isHexString(currentVersion) {
+		pinnedVersion = opts.commentVersion(actionName, comment)
+	}
+	current, ok := semver.Parse(strings.TrimPrefix(pinnedVersion, prefix))
+
+	// The latest release is never a prerelease, so it can only be used when
+	// prereleases are not wanted.
+	if strategy == StrategyMajor && opts.MinAge == 0 && !opts.AllowPrerelease && !current.IsPrerelease() {
+		return opts.resolver().LatestRelease(actionName, currentVersion, prefix)
+	}
+
+	if !ok && strategy != StrategyMajor {
+		slog.Warn("Cannot apply update strategy without a current version",
+			"action", actionName,
+			"strategy", strategy,
+			"hint", "Add a version comment to SHA-pinned actions")
+		return "", "", nil
+	}
+
+	releases, err := opts.resolver().Releases(actionName)
+	if err != nil {
+		return "", "", err
+	}
+	releases = prefixedReleases(releases, prefix)
+	eligible := eligibleReleases(releases, opts.MinAge, time.Now())
+	if len(eligible) < len(releases) {
+		slog.Debug("Skipping releases newer than the minimum age",
+			"action", actionName,
+			"minAge", opts.MinAge,
+			"skipped", len(releases)-len(eligible))
+	}
+	version := selectVersion(eligible, current, strategy, opts.AllowPrerelease)
+	if version == "" {
+		return "", "", nil
+	}
+	version = prefix + version
+
+	sha, err := opts.resolver().TagSHA(actionName, version)
+	if err != nil {
+		return "", "", err
+	}
+	slog.Debug("Selected release for update strategy",
+		"action", actionName,
+		"strategy", strategy,
+		"current", pinnedVersion,
+		"version", version,
+		"sha", sha)
+	return version, sha, nil
+}
+
+// pinTarget returns the version and commit SHA that a ref of an action is pinned to.
+// Tags and branches, including floating ones such as v3 or main, are pinned to the
+// commit they point at now, so that pinning never changes which code runs. Their
+// version is the most specific version tag pointing at the same commit, with the
+// action's tag prefix if it has one, or the ref itself if there is none. SHA refs are
+// already pinned and stay where they are, with the version from their comment, unless
+// opts.UpdatePinned is set. Actions with a configured version resolve as with
+// resolveTarget, and so do SHA refs with opts.UpdatePinned, and tags and branches when
+// there is a lockfile.
+func pinTarget(opts Options, actionName string, ref string, comment string) (string, string, error) {
+	isSHA := len(ref) == 40 && isHexString(ref)
+	if opts.version(actionName) != "" || (isSHA && opts.UpdatePinned) || (opts.Lock != nil && !isSHA) {
+		return resolveTarget(opts, actionName, ref, comment)
+	}
+	if isSHA {
+		return opts.commentVersion(actionName, comment), ref, nil
+	}
+
+	sha, err := opts.resolver().RefSHA(actionName, ref)
+	if err != nil {
+		return "", "", err
+	}
+	if sha == "" {
+		return "", "", fmt.Errorf("%s@%s is neither a tag nor a branch", actionName, ref)
+	}
+
+	tags, err := opts.resolver().TagsForCommit(actionName, sha)
+	if err != nil {
+		return "", "", err
+	}
+	version := mostSpecificTag(tags, ref, opts.tagPrefix(actionName))
+	if version == "" {
+		version = ref
+	}
+
+	slog.Debug("Resolved ref to the commit it points at",
+		"action", actionName,
+		"ref", ref,
+		"version", version,
+		"sha", sha)
+	return version, sha, nil
+}
+
+// mostSpecificTag returns the version tag with the most parts among tags, the highest
+// one if there are several. Tags in the same line as ref are preferred, so that for v3
+// a commit tagged v3, v3.9.1 and v4.0.0 is described as v3.9.1. With a tag prefix, only
+// tags that start with it count, and they are compared without it. It returns an empty
+// string if none of the tags is a version.
+func mostSpecificTag(tags []string, ref string, tagPrefix string) string {
+	refVersion, refOK := semver.Parse(strings.TrimPrefix(ref, tagPrefix))
+	best, bestTag, bestInLine := semver.Version{}, "", false
+	for _, tag := range tags {
+		name, found := strings.CutPrefix(tag, tagPrefix)
+		if !found {
+			continue
+		}
+		v, ok := semver.Parse(name)
+		if !ok {
+			continue
+		}
+		inLine := refOK && v.Major == refVersion.Major && (refVersion.Parts < 2 || v.Minor == refVersion.Minor)
+
+		switch {
+		case bestTag == "":
+		case inLine != bestInLine:
+			if !inLine {
+				continue
+			}
+		case v.Parts != best.Parts:
+			if v.Parts < best.Parts {
+				continue
+			}
+		case semver.Compare(v, best) <= 0:
+			continue
+		}
+		best, bestTag, bestInLine = v, tag, inLine
+	}
+	return bestTag
+}
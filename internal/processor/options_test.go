@@ -0,0 +1,99 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionsIgnored(t *testing.T) {
+	opts := Options{Ignore: []string{"actions/cache", "my-org/*", "docker://postgres", "ghcr.io/org/*"}}
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"actions/cache", true},
+		{"actions/cache/save", true},
+		{"actions/checkout", false},
+		{"my-org/deploy", true},
+		{"my-org/ci/.github/workflows/build.yml", true},
+		{"postgres", true},
+		{"ghcr.io/org/app", true},
+		{"ghcr.io/other/app", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, opts.ignored(tt.name))
+		})
+	}
+}
+
+func TestOptionsVersion(t *testing.T) {
+	opts := Options{Versions: map[string]string{
+		"actions/cache":      "v3",
+		"actions/cache/save": "v4.2.0",
+	}}
+
+	assert.Equal(t, "v3", opts.version("actions/cache"))
+	assert.Equal(t, "v3", opts.version("actions/cache/restore"))
+	assert.Equal(t, "v4.2.0", opts.version("actions/cache/save"))
+	assert.Equal(t, "", opts.version("actions/checkout"))
+	assert.Equal(t, "", Options{}.version("actions/checkout"))
+}
+
+func TestParseCommentStyle(t *testing.T) {
+	style, err := ParseCommentStyle("")
+	assert.NoError(t, err)
+	assert.Equal(t, CommentVersion, style)
+
+	style, err = ParseCommentStyle("pin")
+	assert.NoError(t, err)
+	assert.Equal(t, CommentPin, style)
+
+	_, err = ParseCommentStyle("sha")
+	assert.Error(t, err)
+}
+
+func TestMostSpecificTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []string
+		ref      string
+		prefix   string
+		expected string
+	}{
+		{"full version over major tag", []string{"v4", "v4.2.1", "v4.2"}, "v4", "", "v4.2.1"},
+		{"same line over newer major", []string{"v4.0.0", "v3", "v3.9.1"}, "v3", "", "v3.9.1"},
+		{"same minor line", []string{"v3.8.9", "v3.9.0"}, "v3.8", "", "v3.8.9"},
+		{"release over prerelease", []string{"v5.0.0-rc.2", "v5.0.0"}, "main", "", "v5.0.0"},
+		{"any version for a branch", []string{"latest", "v2.1.0"}, "main", "", "v2.1.0"},
+		{"no version tags", []string{"latest", "nightly"}, "main", "", ""},
+		{"no tags", nil, "v4", "", ""},
+		{"tag prefix", []string{"build-v2.0.0", "deploy-v1", "deploy-v1.3.0"}, "deploy-v1", "deploy-", "deploy-v1.3.0"},
+		{"other tag prefix", []string{"build-v2.0.0"}, "main", "deploy-", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, mostSpecificTag(tt.tags, tt.ref, tt.prefix))
+		})
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor
+
+import (
+	"log/slog"
+
+	"github.com/behnh/actions-toolkit/internal/file"
+)
+
+// FindOutdatedPins checks every SHA-pinned action in the given files for the release
+// that update would move it to: its configured version, or the newest release its
+// update strategy allows that is at least MinAge old. Files are never changed, and
+// ignored actions are not checked. It returns a result for each SHA-pinned action,
+// which is changed if the action would move, or for each file that could not be
+// processed. Actions are resolved by up to opts.Concurrency workers at the same time.
+func FindOutdatedPins(files []string, opts Options) []Result {
+	return findOutdatedPins(readWorkflowFiles(files), opts)
+}
+
+// findOutdatedPins is FindOutdatedPins for files that have already been read and parsed.
+func findOutdatedPins(workflows []workflowFile, opts Options) []Result {
+	var results []Result
+
+	var pinned []file.Uses
+	var targets []target
+	for _, workflow := range workflows {
+		if workflow.err != nil {
+			results = append(results, fileError(workflow.path, workflow.err))
+			continue
+		}
+
+		for _, u := range workflow.uses {
+			if u.IsImage() {
+				continue
+			}
+			actionName, currentVersion, ok := splitUses(u.Value)
+			if !ok || !(len(currentVersion) == 40 && isHexString(currentVersion)) || opts.ignored(actionName) {
+				continue
+			}
+			pinned = append(pinned, u)
+			targets = append(targets, newTarget(u, actionName, currentVersion))
+		}
+	}
+
+	prefetchLatestReleases(opts, targets)
+	resolved := resolveTargets(opts, targets, resolveTarget)
+
+	for i, u := range pinned {
+		result := newResult(u)
+		t := targets[i]
+		resolution := resolved[t]
+		switch {
+		case resolution.err != nil:
+			slog.Error("Failed to get latest release", "action", t.action, "error", resolution.err)
+			result = result.fail(resolution.err)
+		case resolution.version == "" || resolution.sha == "":
+			slog.Info("No release found for action", "action", t.action)
+			result.Status = StatusSkipped
+		case resolution.sha == t.ref:
+			result.Status = StatusUnchanged
+		default:
+			slog.Info("Pinned SHA is outdated",
+				"action", t.action,
+				"sha", t.ref,
+				"latest", resolution.version,
+				"latestSHA", resolution.sha,
+				"file", u.File,
+				"line", u.Line)
+			result.Status = StatusChanged
+			result.NewRef = resolution.sha
+			result.NewVersion = resolution.version
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
@@ -0,0 +1,59 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/behnh/actions-toolkit/internal/processor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindOutdatedPins(t *testing.T) {
+	path := writeWorkflow(t,
+		"actions/setup-node@cdca7365b2dadb8aad0a33bc7601856ffabcc48e # v4.3.0",
+		"actions/cache@5a3ec84eff668545956fd18022155c47e93e2684 # v4.2.3",
+		"actions/cache@v3",
+		"aws-actions/toolkit/deploy@cccccccccccccccccccccccccccccccccccccccc # deploy-v1.2.0",
+	)
+	before, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	opts := processor.Options{
+		Write:       true,
+		TagPrefixes: map[string]string{"aws-actions/toolkit/deploy": "deploy-"},
+		Resolver:    fixtureResolver(t),
+	}
+	results := processor.FindOutdatedPins([]string{path}, opts)
+	assert.Len(t, results, 3)
+
+	assert.Equal(t, processor.StatusChanged, results[0].Status)
+	assert.Equal(t, "49933ea5288caeca8642d1e84afbd3f7d6820020", results[0].NewRef)
+	assert.Equal(t, "v4.4.0", results[0].NewVersion)
+
+	assert.Equal(t, processor.StatusUnchanged, results[1].Status)
+
+	// The latest release of a monorepo action is the latest one with its prefix
+	assert.Equal(t, processor.StatusChanged, results[2].Status)
+	assert.Equal(t, "deploy-v1.3.0", results[2].NewVersion)
+
+	// Files are never changed
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(before), string(content))
+}
@@ -18,151 +18,220 @@ package processor
 
 import (
 	"fmt"
-	"github.com/aymanbagabas/go-udiff"
+	"log/slog"
 	"os"
-	"strings"
 
+	"github.com/aymanbagabas/go-udiff"
 	"github.com/behnh/actions-toolkit/internal/file"
-	"github.com/behnh/actions-toolkit/internal/github"
-	"log/slog"
+	"github.com/behnh/actions-toolkit/internal/semver"
 )
 
-func PinAllActions(files []string, token string, write bool) {
+// PinAllActions pins every action in the given files to a commit SHA, and every
+// container image to its digest. Tags and branches are pinned to the commit they point
+// at now, commented with the most specific version tag of that commit, so that pinning
+// does not upgrade anything and pinning again changes nothing. SHA refs are left alone,
+// unless opts.UpdatePinned is set, in which case they are moved as resolveTarget
+// describes. Actions with a configured version are pinned to that version instead. With a
+// lockfile, tags and branches are pinned to the commits recorded in it, and images are
+// skipped. Ignored actions and images are skipped. It returns a result for each uses
+// value, or for each file that could not be processed, in the order of the files.
+// Actions are resolved by up to opts.Concurrency workers at the same time.
+func PinAllActions(files []string, opts Options) []Result {
+	// Read and parse every file up front, so that the refs they use can be resolved
+	// concurrently before any file is changed
+	return pinAllActions(readWorkflowFiles(files), opts)
+}
+
+// pinAllActions is PinAllActions for files that have already been read and parsed.
+func pinAllActions(workflows []workflowFile, opts Options) []Result {
+	var results []Result
+
 	// Get all unique actions from the files
-	actions := FindActionsInFiles(files)
+	actions := uniqueActions(workflows)
 	slog.Info("Found actions to pin", "count", len(actions), "actions", actions)
 
-	// Process each file for each action
-	for _, f := range files {
-		// Read the file
-		content, err := file.ReadFile(f)
-		if err != nil {
-			slog.Error("Failed to read file", "file", f, "error", err)
+	var targets []target
+	for _, workflow := range workflows {
+		if workflow.err != nil {
 			continue
 		}
+		for _, u := range workflow.uses {
+			if u.IsImage() {
+				continue
+			}
+			if actionName, currentVersion, ok := splitUses(u.Value); ok && !opts.ignored(actionName) {
+				targets = append(targets, newTarget(u, actionName, currentVersion))
+			}
+		}
+	}
+	if opts.UpdatePinned {
+		// Only updated SHA refs resolve to their latest release
+		var pinned []target
+		for _, t := range targets {
+			if len(t.ref) == 40 && isHexString(t.ref) {
+				pinned = append(pinned, t)
+			}
+		}
+		prefetchLatestReleases(opts, pinned)
+	}
+	resolved := resolveTargets(opts, targets, pinTarget)
 
-		contentModified := false
-		contentStr := string(content)
-
-		// Parse the file for 'uses' values
-		usesValues, err := file.ParseYAMLForUses(content)
-		if err != nil {
-			slog.Error("Failed to parse file", "file", f, "error", err)
+	// Process each file, in order
+	for _, workflow := range workflows {
+		f := workflow.path
+		if workflow.err != nil {
+			results = append(results, fileError(f, workflow.err))
 			continue
 		}
+		content, usesValues := workflow.content, workflow.uses
+
+		var edits []file.Edit
+		var fileResults []Result
 
 		// Process each uses value in the file
-		for _, uses := range usesValues {
-			parts := strings.Split(uses, "@")
-			if len(parts) != 2 {
+		for _, u := range usesValues {
+			// Container images are pinned to digests rather than commit SHAs
+			if u.IsImage() {
+				result := newResult(u)
+				if opts.ignored(result.Action) {
+					slog.Debug("Skipping ignored image", "image", u.Value, "file", f)
+					result.Status = StatusSkipped
+					fileResults = append(fileResults, result)
+					continue
+				}
+				if opts.Lock != nil {
+					slog.Warn("Skipping image, digests cannot be resolved offline", "image", u.Value, "file", f)
+					result.Status = StatusSkipped
+					fileResults = append(fileResults, result)
+					continue
+				}
+				imageEdits, result := pinImage(content, u, result, opts.resolver(), opts.CommentStyle)
+				if result.Status == StatusError {
+					slog.Error("Failed to get image digest", "image", u.Value, "file", f, "error", result.Error)
+				}
+				edits = append(edits, imageEdits...)
+				fileResults = append(fileResults, result)
 				continue
 			}
 
-			actionName := strings.TrimSpace(parts[0])
-			currentVersion := parts[1]
+			actionName, currentVersion, ok := splitUses(u.Value)
+			if !ok {
+				continue
+			}
+			result := newResult(u)
 
-			// Skip if version is "main"
-			if currentVersion == "main" {
-				slog.Debug("Skipping action with 'main' version", "action", actionName, "file", f)
+			if opts.ignored(actionName) {
+				slog.Debug("Skipping ignored action", "action", actionName, "file", f)
+				result.Status = StatusSkipped
+				fileResults = append(fileResults, result)
 				continue
 			}
 
-			slog.Debug("Processing action", "action", actionName, "version", currentVersion, "file", f)
+			slog.Debug("Processing action", "action", actionName, "version", currentVersion, "file", f, "line", u.Line)
 
-			// Get the latest release with SHA
-			latestRelease, latestSHA, err := github.GetLatestReleaseWithSHA(token, actionName, currentVersion)
+			// Get the commit the ref points at, or the configured version, with its version
+			resolution := resolved[newTarget(u, actionName, currentVersion)]
+			latestRelease, latestSHA, err := resolution.version, resolution.sha, resolution.err
 			if err != nil {
 				slog.Error("Failed to get latest release", "action", actionName, "error", err)
-				continue
-			}
-
-			if latestRelease == "" || latestSHA == "" {
-				slog.Info("No release or SHA found for action", "action", actionName)
+				fileResults = append(fileResults, result.fail(err))
 				continue
 			}
 
 			// Check if current version is already a SHA
 			isSHA := len(currentVersion) == 40 && isHexString(currentVersion)
 
-			// Check if update is needed
+			// SHAs stay where they are unless they are updated, even without a version comment
 			if isSHA && currentVersion == latestSHA {
-				slog.Info("Action is already pinned to latest SHA", "action", actionName, "file", f)
+				slog.Info("Action is already pinned", "action", actionName, "file", f)
+				result.Status = StatusUnchanged
+				fileResults = append(fileResults, result)
 				continue
 			}
 
-			// Update the action to use the SHA
-			lines := strings.Split(contentStr, "\n")
-			newLines := make([]string, len(lines))
-
-			for i, line := range lines {
-				if strings.Contains(line, actionName+"@"+currentVersion) {
-					// Replace with SHA
-					updatedAction := fmt.Sprintf("%s@%s", actionName, latestSHA)
-					updatedLine := strings.Replace(line, actionName+"@"+currentVersion, updatedAction, 1)
-					newLines[i] = updateVersionComment(updatedLine, latestRelease)
-
-					slog.Debug("Updated line", "originalLine", line, "updatedLine", newLines[i])
-				} else {
-					newLines[i] = line
-				}
+			if latestRelease == "" || latestSHA == "" {
+				slog.Info("No release or SHA found for action", "action", actionName)
+				result.Status = StatusSkipped
+				fileResults = append(fileResults, result)
+				continue
 			}
 
-			contentStr = strings.Join(newLines, "\n")
-			contentModified = true
+			// Update the action to use the SHA
+			edits = append(edits, rewriteUses(content, u, actionName+"@"+latestSHA, latestRelease, opts.tagPrefix(actionName), opts.CommentStyle)...)
+			result.Status = StatusChanged
+			result.NewRef = latestSHA
+			result.NewVersion = latestRelease
+			fileResults = append(fileResults, result)
 
 			slog.Debug("Updated action in memory",
 				"action", actionName,
 				"from", currentVersion,
 				"to", latestSHA,
 				"version", latestRelease,
-				"file", f)
+				"file", f,
+				"line", u.Line)
+		}
+
+		results = append(results, fileResults...)
+
+		if len(edits) == 0 {
+			slog.Info("No changes to file", "file", f)
+			continue
+		}
+
+		newContent, err := file.ApplyEdits(content, edits)
+		if err != nil {
+			slog.Error("Failed to apply changes", "file", f, "error", err)
+			results = append(results, fileError(f, err))
+			continue
 		}
 
 		// Write changes to file if needed
-		if contentModified && write {
-			err = os.WriteFile(f, []byte(contentStr), 0644)
+		if opts.Write {
+			err = os.WriteFile(f, newContent, 0644)
 			if err != nil {
 				slog.Error("Failed to write file", "file", f, "error", err)
+				results = append(results, fileError(f, err))
 				continue
 			}
 			slog.Info("Successfully updated file with pinned actions", "file", f)
-		} else if contentModified {
-			slog.Info(fmt.Sprintf("Dry run - not updating file. Would have applied:\n%s\n", udiff.Unified(f, f, string(content), contentStr)))
 		} else {
-			slog.Info("No changes to file", "file", f)
+			slog.Info(fmt.Sprintf("Dry run - not updating file. Would have applied:\n%s\n", udiff.Unified(f, f, string(content), string(newContent))))
 		}
 	}
+
+	return results
 }
 
-// IsVersionNumber checks if a string looks like a version number (e.g., 1.2.3 or 1.2)
+// IsVersionNumber checks if a string looks like a version number (e.g., 1.2.3, 1.2 or
+// v5.0.0-beta.1), as opposed to a major version tag such as v4
 func IsVersionNumber(s string) bool {
-	// Remove 'v' prefix if it exists to standardize the check
-	if strings.HasPrefix(s, "v") {
-		s = s[1:]
-	}
-
-	// Check if the string contains digits and dots only
-	for _, c := range s {
-		if (c < '0' || c > '9') && c != '.' {
-			return false
-		}
-	}
-
-	// Must contain at least one dot for a version number
-	return strings.Contains(s, ".")
+	v, ok := semver.Parse(s)
+	return ok && v.Parts > 1
 }
 
-func PinAction(files []string, actionName string, version, token string, write bool) {
+// PinAction pins every use of an action in the given files to the commit SHA of the
+// given version. It returns a result for each use of the action, or for each file
+// that could not be processed.
+func PinAction(files []string, actionName string, version string, opts Options) []Result {
+	var results []Result
+
 	// Get the SHA for the specific version once, outside the file loop
-	_, latestSHA, err := github.GetLatestReleaseWithSHA(token, actionName, version)
+	var latestSHA string
+	var err error
+	if opts.Lock != nil {
+		_, latestSHA, err = lockedTarget(opts.Lock, actionName, version)
+	} else {
+		latestSHA, err = opts.resolver().TagSHA(actionName, version)
+	}
 	if err != nil {
 		slog.Error("Failed to get SHA for version", "action", actionName, "version", version, "error", err)
-		return
+		return []Result{{Action: actionName, NewVersion: version, Status: StatusError, Error: err.Error()}}
 	}
 
 	if latestSHA == "" {
 		slog.Warn("No SHA found for version, skipping this action", "action", actionName, "version", version)
-		return
+		return []Result{{Action: actionName, NewVersion: version, Status: StatusError, Error: "no SHA found for version"}}
 	}
 
 	for _, f := range files {
@@ -170,78 +239,59 @@ func PinAction(files []string, actionName string, version, token string, write b
 		content, err := file.ReadFile(f)
 		if err != nil {
 			slog.Error("Failed to read file", "file", f, "error", err)
+			results = append(results, fileError(f, err))
 			continue
 		}
 
-		contentModified := false
-		contentStr := string(content)
-
 		// Parse the file for 'uses' values
-		usesValues, err := file.ParseYAMLForUses(content)
+		usesValues, err := file.ParseUses(f, content)
 		if err != nil {
 			slog.Error("Failed to parse file", "file", f, "error", err)
+			results = append(results, fileError(f, err))
 			continue
 		}
 
 		// Find actions that need to be updated in this file
-		actionsToUpdate := make(map[string]string)
-
-		for _, uses := range usesValues {
-			if strings.HasPrefix(uses, actionName+"@") {
-				parts := strings.Split(uses, "@")
-				if len(parts) != 2 {
-					continue
-				}
+		var edits []file.Edit
 
-				currentVersion := parts[1]
+		for _, u := range usesValues {
+			if u.IsImage() {
+				continue
+			}
+			name, currentVersion, ok := splitUses(u.Value)
+			if !ok || name != actionName {
+				continue
+			}
+			result := newResult(u)
 
-				// Skip if version is "main"
-				if currentVersion == "main" {
-					slog.Debug("Skipping action with 'main' version", "action", actionName, "file", f)
-					continue
-				}
+			// Skip if version is "main"
+			if currentVersion == "main" {
+				slog.Debug("Skipping action with 'main' version", "action", actionName, "file", f)
+				result.Status = StatusSkipped
+				results = append(results, result)
+				continue
+			}
 
-				// Check if current version is already a SHA
-				isSHA := len(currentVersion) == 40 && isHexString(currentVersion)
+			// Check if current version is already a SHA
+			isSHA := len(currentVersion) == 40 && isHexString(currentVersion)
 
-				if isSHA {
-					slog.Debug("Action is already using a SHA, no need to update", "action", actionName, "sha", currentVersion, "file", f)
-				} else {
-					actionsToUpdate[actionName] = currentVersion
-				}
+			if isSHA {
+				slog.Debug("Action is already using a SHA, no need to update", "action", actionName, "sha", currentVersion, "file", f)
+				result.Status = StatusUnchanged
+				results = append(results, result)
+				continue
 			}
-		}
-
-		// Skip to next file if no actions need updating in this file
-		if len(actionsToUpdate) == 0 {
-			slog.Info("No actions to update in this file", "file", f)
-			continue
-		}
 
-		for _, currentVersion := range actionsToUpdate {
-			slog.Debug("Updating action", "action", actionName, "from", currentVersion, "to", latestSHA, "file", f)
+			slog.Debug("Updating action", "action", actionName, "from", currentVersion, "to", latestSHA, "file", f, "line", u.Line)
 
 			// Update to the specified SHA
-			lines := strings.Split(contentStr, "\n")
-			newLines := make([]string, len(lines))
-
-			for i, line := range lines {
-				if strings.Contains(line, actionName+"@"+currentVersion) {
-					// Replace with SHA
-					updatedAction := fmt.Sprintf("%s@%s", actionName, latestSHA)
-					updatedLine := strings.Replace(line, actionName+"@"+currentVersion, updatedAction, 1)
-					newLines[i] = updateVersionComment(updatedLine, version)
-
-					slog.Debug("Updated line", "originalLine", line, "updatedLine", newLines[i])
-				} else {
-					newLines[i] = line
-				}
-			}
-
-			contentStr = strings.Join(newLines, "\n")
-			contentModified = true
+			edits = append(edits, rewriteUses(content, u, actionName+"@"+latestSHA, version, opts.tagPrefix(actionName), opts.CommentStyle)...)
+			result.Status = StatusChanged
+			result.NewRef = latestSHA
+			result.NewVersion = version
+			results = append(results, result)
 
-			if write {
+			if opts.Write {
 				slog.Debug("Updated action in memory",
 					"action", actionName,
 					"from", currentVersion,
@@ -256,14 +306,29 @@ func PinAction(files []string, actionName string, version, token string, write b
 			}
 		}
 
-		// Write the updated content back to the file if it was modified and write is enabled
-		if contentModified && write {
-			err = os.WriteFile(f, []byte(contentStr), 0644)
+		// Skip to next file if no actions need updating in this file
+		if len(edits) == 0 {
+			slog.Info("No actions to update in this file", "file", f)
+			continue
+		}
+
+		// Write the updated content back to the file if write is enabled
+		if opts.Write {
+			newContent, err := file.ApplyEdits(content, edits)
+			if err != nil {
+				slog.Error("Failed to apply changes", "file", f, "error", err)
+				results = append(results, fileError(f, err))
+				continue
+			}
+			err = os.WriteFile(f, newContent, 0644)
 			if err != nil {
 				slog.Error("Failed to write file", "file", f, "error", err)
+				results = append(results, fileError(f, err))
 				continue
 			}
 			slog.Info("Successfully wrote file with pinned action", "file", f)
 		}
 	}
+
+	return results
 }
@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/behnh/actions-toolkit/internal/fixtures"
 	"github.com/behnh/actions-toolkit/internal/processor"
 	"github.com/stretchr/testify/assert"
 )
@@ -61,9 +62,19 @@ func TestIsVersionNumber(t *testing.T) {
 			input:    "",
 			expected: false,
 		},
+		{
+			name:     "valid prerelease version",
+			input:    "v5.0.0-beta.1",
+			expected: true,
+		},
+		{
+			name:     "valid version with build metadata",
+			input:    "1.2+build.7",
+			expected: true,
+		},
 		{
 			name:     "invalid version - contains other characters",
-			input:    "1.2-beta",
+			input:    "1.2_beta",
 			expected: false,
 		},
 	}
@@ -77,39 +88,30 @@ func TestIsVersionNumber(t *testing.T) {
 }
 
 func TestPinAction(t *testing.T) {
-	// Create a temporary directory for test files
-	tempDir, err := os.MkdirTemp("", "pinning-test")
-	assert.NoError(t, err)
-	defer os.RemoveAll(tempDir)
+	resolver := fixtureResolver(t)
 
 	// Test scenarios
 	tests := []struct {
-		name            string
-		fixtureFile     string
-		actionName      string
-		version         string
-		mockToken       string
-		write           bool
-		setupMockGithub func() // Function to setup GitHub API mocks if needed
-		verify          func(t *testing.T, tempFile string)
+		name        string
+		fixtureFile string
+		actionName  string
+		version     string
+		write       bool
+		verify      func(t *testing.T, tempFile string)
 	}{
 		{
 			name:        "pin action with semver",
 			fixtureFile: "workflow_semver.yaml",
 			actionName:  "actions/setup-node",
 			version:     "v4.3.0",
-			mockToken:   "mock-token",
 			write:       true,
-			setupMockGithub: func() {
-				// In a real implementation, you would mock the GitHub API
-			},
 			verify: func(t *testing.T, tempFile string) {
 				content, err := os.ReadFile(tempFile)
 				assert.NoError(t, err)
 
-				// Since we're using a mock token, the GitHub API call will fail
-				// The file should still contain the original version
-				assert.Contains(t, string(content), "actions/setup-node@v4.3.0")
+				// The action is pinned to the commit of the requested version
+				assert.Contains(t, string(content),
+					"actions/setup-node@cdca7365b2dadb8aad0a33bc7601856ffabcc48e # v4.3.0")
 			},
 		},
 		{
@@ -117,19 +119,14 @@ func TestPinAction(t *testing.T) {
 			fixtureFile: "workflow_sha.yaml",
 			actionName:  "actions/setup-node",
 			version:     "v4.3.0",
-			mockToken:   "mock-token",
 			write:       true,
-			setupMockGithub: func() {
-				// In a real implementation, you would mock the GitHub API
-			},
 			verify: func(t *testing.T, tempFile string) {
 				content, err := os.ReadFile(tempFile)
 				assert.NoError(t, err)
 
-				// Since we're using a mock token, the GitHub API call will fail
-				// The file should still contain the original SHA
-				assert.Contains(t, string(content), 
-					"actions/setup-node@cdca7365b2dadb8aad0a33bc7601856ffabcc48e")
+				// Actions that are already pinned are left alone
+				assert.Contains(t, string(content),
+					"actions/setup-node@cdca7365b2dadb8aad0a33bc7601856ffabcc48e # v4.3.0")
 			},
 		},
 		{
@@ -137,18 +134,13 @@ func TestPinAction(t *testing.T) {
 			fixtureFile: "workflow_major_version.yaml",
 			actionName:  "actions/cache",
 			version:     "v3",
-			mockToken:   "mock-token",
 			write:       true,
-			setupMockGithub: func() {
-				// In a real implementation, you would mock the GitHub API
-			},
 			verify: func(t *testing.T, tempFile string) {
 				content, err := os.ReadFile(tempFile)
 				assert.NoError(t, err)
 
-				// Since we're using a mock token, the GitHub API call will fail
-				// The file should still contain the original version
-				assert.Contains(t, string(content), "actions/cache@v3")
+				assert.Contains(t, string(content),
+					"actions/cache@2f8e54208210a422b2efd51efaa6bd6d7ca8920f # v3")
 			},
 		},
 		{
@@ -156,11 +148,7 @@ func TestPinAction(t *testing.T) {
 			fixtureFile: "no_actions.yaml",
 			actionName:  "actions/setup-node",
 			version:     "v4.3.0",
-			mockToken:   "mock-token",
 			write:       true,
-			setupMockGithub: func() {
-				// In a real implementation, you would mock the GitHub API
-			},
 			verify: func(t *testing.T, tempFile string) {
 				content, err := os.ReadFile(tempFile)
 				assert.NoError(t, err)
@@ -174,11 +162,7 @@ func TestPinAction(t *testing.T) {
 			fixtureFile: "workflow_with_main.yaml",
 			actionName:  "actions/setup-node",
 			version:     "v4.3.0",
-			mockToken:   "mock-token",
 			write:       true,
-			setupMockGithub: func() {
-				// In a real implementation, you would mock the GitHub API
-			},
 			verify: func(t *testing.T, tempFile string) {
 				content, err := os.ReadFile(tempFile)
 				assert.NoError(t, err)
@@ -192,91 +176,147 @@ func TestPinAction(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Copy the fixture file to a temporary location
-			fixtureFile := filepath.Join("..", "..", "snapshots", tt.fixtureFile)
-			tempFile := filepath.Join(tempDir, tt.fixtureFile)
-			
-			// Read the fixture file
-			content, err := os.ReadFile(fixtureFile)
-			assert.NoError(t, err)
-			
-			// Write to the temp file
-			err = os.WriteFile(tempFile, content, 0644)
-			assert.NoError(t, err)
-			
-			// Setup mock GitHub API if needed
-			if tt.setupMockGithub != nil {
-				tt.setupMockGithub()
-			}
-			
+			tempFile := copySnapshot(t, t.TempDir(), tt.fixtureFile)
+
 			// Call the function being tested
-			processor.PinAction([]string{tempFile}, tt.actionName, tt.version, tt.mockToken, tt.write)
-			
+			results := processor.PinAction([]string{tempFile}, tt.actionName, tt.version, processor.Options{Write: tt.write, Resolver: resolver})
+			assert.Zero(t, processor.Summarize(results).Failed)
+
 			// Verify the results
-			if tt.verify != nil {
-				tt.verify(t, tempFile)
+			tt.verify(t, tempFile)
+		})
+	}
+}
+
+func TestPinActionUnknownVersion(t *testing.T) {
+	tempFile := copySnapshot(t, t.TempDir(), "workflow_semver.yaml")
+
+	results := processor.PinAction([]string{tempFile}, "actions/setup-node", "v9.9.9", processor.Options{Write: true, Resolver: fixtureResolver(t)})
+	assert.Equal(t, processor.Summary{Total: 1, Failed: 1}, processor.Summarize(results))
+}
+
+func TestPinAllActionsWithoutReleases(t *testing.T) {
+	path := writeWorkflow(t, "octo-org/tags-only@v1")
+
+	results := processor.PinAllActions([]string{path}, processor.Options{Write: true, Resolver: fixtureResolver(t)})
+	assert.Equal(t, processor.Summary{Total: 1, Changed: 1}, processor.Summarize(results))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "octo-org/tags-only@fedcba9876543210fedcba9876543210fedcba98 # v1.10.0\n")
+}
+
+func TestPinAllActionsTwice(t *testing.T) {
+	path := writeWorkflow(t, "actions/setup-node@v3", "actions/setup-node@main", "actions/cache@v4")
+	opts := processor.Options{Write: true, Resolver: fixtureResolver(t)}
+
+	results := processor.PinAllActions([]string{path}, opts)
+	assert.Equal(t, processor.Summary{Total: 3, Changed: 3}, processor.Summarize(results))
+	pinned, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	// Pinned SHAs stay where they are, even where newer releases exist
+	results = processor.PinAllActions([]string{path}, opts)
+	assert.Equal(t, processor.Summary{Total: 3}, processor.Summarize(results))
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(pinned), string(content))
+	assert.Contains(t, string(content), "actions/setup-node@3235b876344d2a9aa001b8d1453c930bba69e610 # v3.9.1")
+	assert.Contains(t, string(content), "actions/setup-node@802632921f8532d2409ae6eac3313b6f81f11122 # main")
+}
+
+func TestPinAllActionsUpdatePinned(t *testing.T) {
+	tests := []struct {
+		name            string
+		uses            string
+		allowPrerelease bool
+		expected        string
+	}{
+		{
+			name:     "prereleases are left out by default",
+			uses:     "actions/setup-node@cdca7365b2dadb8aad0a33bc7601856ffabcc48e # v4.3.0",
+			expected: "actions/setup-node@49933ea5288caeca8642d1e84afbd3f7d6820020 # v4.4.0",
+		},
+		{
+			name:            "prereleases are adopted when allowed",
+			uses:            "actions/setup-node@cdca7365b2dadb8aad0a33bc7601856ffabcc48e # v4.3.0",
+			allowPrerelease: true,
+			expected:        "actions/setup-node@5e21ff4d9bc1a8cf6de233a3057d20ec6b3fb69d # v5.0.0-beta.2",
+		},
+		{
+			name:     "a prerelease moves on to newer prereleases of its version",
+			uses:     "actions/setup-node@1d0ff469b7ec7b3cb9d8673fde0c81c44821de2a # v5.0.0-beta.1",
+			expected: "actions/setup-node@5e21ff4d9bc1a8cf6de233a3057d20ec6b3fb69d # v5.0.0-beta.2",
+		},
+		{
+			name:     "an action with a tag prefix moves to its own latest release",
+			uses:     "aws-actions/toolkit/deploy@cccccccccccccccccccccccccccccccccccccccc # deploy-v1.2.0",
+			expected: "aws-actions/toolkit/deploy@bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb # deploy-v1.3.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeWorkflow(t, tt.uses)
+
+			opts := processor.Options{
+				Write:           true,
+				UpdatePinned:    true,
+				AllowPrerelease: tt.allowPrerelease,
+				TagPrefixes:     map[string]string{"aws-actions/toolkit/deploy": "deploy-"},
+				Resolver:        fixtureResolver(t),
 			}
+			results := processor.PinAllActions([]string{path}, opts)
+			assert.Equal(t, processor.Summary{Total: 1, Changed: 1}, processor.Summarize(results))
+
+			content, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Contains(t, string(content), tt.expected)
 		})
 	}
 }
 
 func TestPinAllActions(t *testing.T) {
-	// Create a temporary directory for test files
-	tempDir, err := os.MkdirTemp("", "pinning-test")
-	assert.NoError(t, err)
-	defer os.RemoveAll(tempDir)
+	resolver := fixtureResolver(t)
 
 	// Test scenarios
 	tests := []struct {
-		name            string
-		fixtureFiles    []string
-		mockToken       string
-		write           bool
-		setupMockGithub func() // Function to setup GitHub API mocks if needed
-		verify          func(t *testing.T, tempFiles []string)
+		name         string
+		fixtureFiles []string
+		write        bool
+		verify       func(t *testing.T, tempFiles []string)
 	}{
 		{
 			name:         "pin all actions in multiple files",
 			fixtureFiles: []string{"workflow_semver.yaml", "workflow_sha.yaml", "workflow_major_version.yaml"},
-			mockToken:    "mock-token",
 			write:        true,
-			setupMockGithub: func() {
-				// In a real implementation, you would mock the GitHub API
-			},
 			verify: func(t *testing.T, tempFiles []string) {
-				// Since we're using a mock token, the GitHub API calls will fail
-				// The files should remain unchanged
-				
-				// Check the semver file
+				// Check the semver file, which is pinned to the commit of its tag
 				content, err := os.ReadFile(tempFiles[0])
 				assert.NoError(t, err)
-				assert.Contains(t, string(content), "actions/setup-node@v4.3.0")
-				
-				// Check the SHA file
+				assert.Contains(t, string(content), "actions/setup-node@cdca7365b2dadb8aad0a33bc7601856ffabcc48e # v4.3.0")
+
+				// Check the SHA file, whose pin is left alone although v4.4.0 is newer
 				content, err = os.ReadFile(tempFiles[1])
 				assert.NoError(t, err)
-				assert.Contains(t, string(content), 
-					"actions/setup-node@cdca7365b2dadb8aad0a33bc7601856ffabcc48e")
-				
-				// Check the major version file
+				assert.Contains(t, string(content), "actions/setup-node@cdca7365b2dadb8aad0a33bc7601856ffabcc48e # v4.3.0")
+
+				// Check the major version file, which stays on what v3 points at rather than moving to v4
 				content, err = os.ReadFile(tempFiles[2])
 				assert.NoError(t, err)
-				assert.Contains(t, string(content), "actions/cache@v3")
+				assert.Contains(t, string(content), "actions/cache@2f8e54208210a422b2efd51efaa6bd6d7ca8920f # v3.4.3")
 			},
 		},
 		{
 			name:         "pin all actions with some files having no actions",
 			fixtureFiles: []string{"workflow_semver.yaml", "no_actions.yaml"},
-			mockToken:    "mock-token",
 			write:        true,
-			setupMockGithub: func() {
-				// In a real implementation, you would mock the GitHub API
-			},
 			verify: func(t *testing.T, tempFiles []string) {
 				// Check the semver file
 				content, err := os.ReadFile(tempFiles[0])
 				assert.NoError(t, err)
-				assert.Contains(t, string(content), "actions/setup-node@v4.3.0")
-				
+				assert.Contains(t, string(content), "actions/setup-node@cdca7365b2dadb8aad0a33bc7601856ffabcc48e # v4.3.0")
+
 				// Check the no actions file
 				content, err = os.ReadFile(tempFiles[1])
 				assert.NoError(t, err)
@@ -284,23 +324,29 @@ func TestPinAllActions(t *testing.T) {
 			},
 		},
 		{
-			name:         "pin all actions with some files having main version",
+			name:         "pin all actions with some files on a branch",
 			fixtureFiles: []string{"workflow_semver.yaml", "workflow_with_main.yaml"},
-			mockToken:    "mock-token",
 			write:        true,
-			setupMockGithub: func() {
-				// In a real implementation, you would mock the GitHub API
-			},
 			verify: func(t *testing.T, tempFiles []string) {
 				// Check the semver file
 				content, err := os.ReadFile(tempFiles[0])
 				assert.NoError(t, err)
-				assert.Contains(t, string(content), "actions/setup-node@v4.3.0")
-				
-				// Check the main version file
+				assert.Contains(t, string(content), "actions/setup-node@cdca7365b2dadb8aad0a33bc7601856ffabcc48e # v4.3.0")
+
+				// Check the main version file, which is pinned to the head of the branch
 				content, err = os.ReadFile(tempFiles[1])
 				assert.NoError(t, err)
-				assert.Contains(t, string(content), "actions/setup-node@main")
+				assert.Contains(t, string(content), "actions/setup-node@802632921f8532d2409ae6eac3313b6f81f11122 # main")
+			},
+		},
+		{
+			name:         "dry run leaves files unchanged",
+			fixtureFiles: []string{"workflow_semver.yaml"},
+			write:        false,
+			verify: func(t *testing.T, tempFiles []string) {
+				content, err := os.ReadFile(tempFiles[0])
+				assert.NoError(t, err)
+				assert.Contains(t, string(content), "actions/setup-node@v4.3.0\n")
 			},
 		},
 	}
@@ -308,33 +354,120 @@ func TestPinAllActions(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Copy the fixture files to temporary locations
+			tempDir := t.TempDir()
 			tempFiles := make([]string, len(tt.fixtureFiles))
 			for i, fixtureFile := range tt.fixtureFiles {
-				fixtureFilePath := filepath.Join("..", "..", "snapshots", fixtureFile)
-				tempFilePath := filepath.Join(tempDir, fixtureFile)
-				tempFiles[i] = tempFilePath
-				
-				// Read the fixture file
-				content, err := os.ReadFile(fixtureFilePath)
-				assert.NoError(t, err)
-				
-				// Write to the temp file
-				err = os.WriteFile(tempFilePath, content, 0644)
-				assert.NoError(t, err)
+				tempFiles[i] = copySnapshot(t, tempDir, fixtureFile)
 			}
-			
-			// Setup mock GitHub API if needed
-			if tt.setupMockGithub != nil {
-				tt.setupMockGithub()
-			}
-			
+
 			// Call the function being tested
-			processor.PinAllActions(tempFiles, tt.mockToken, tt.write)
-			
+			results := processor.PinAllActions(tempFiles, processor.Options{Write: tt.write, Resolver: resolver})
+			assert.Zero(t, processor.Summarize(results).Failed)
+
 			// Verify the results
-			if tt.verify != nil {
-				tt.verify(t, tempFiles)
+			tt.verify(t, tempFiles)
+		})
+	}
+}
+
+// fixtureResolver returns a resolver that answers from the fixtures in the snapshots
+// directory, so that tests do not depend on GitHub.
+// prefetchingResolver is a fixture resolver that records the actions whose latest
+// releases are prefetched.
+type prefetchingResolver struct {
+	*fixtures.Resolver
+	prefetched [][]string
+}
+
+func (r *prefetchingResolver) PrefetchLatestReleases(actionNames []string) {
+	r.prefetched = append(r.prefetched, actionNames)
+}
+
+func TestPrefetchLatestReleases(t *testing.T) {
+	path := writeWorkflow(t,
+		"actions/setup-node@cdca7365b2dadb8aad0a33bc7601856ffabcc48e # v4.3.0",
+		"actions/cache@v4",
+		"actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683 # v4.2.2")
+
+	// Pinning only resolves the latest release of SHA refs that are updated
+	resolver := &prefetchingResolver{Resolver: fixtureResolver(t)}
+	processor.PinAllActions([]string{path}, processor.Options{Resolver: resolver})
+	assert.Empty(t, resolver.prefetched)
+
+	processor.PinAllActions([]string{path}, processor.Options{UpdatePinned: true, Resolver: resolver})
+	assert.Equal(t, [][]string{{"actions/setup-node", "actions/checkout"}}, resolver.prefetched)
+
+	resolver = &prefetchingResolver{Resolver: fixtureResolver(t)}
+	processor.UpdateAction(path, "actions/cache", processor.Options{Resolver: resolver})
+	assert.Equal(t, [][]string{{"actions/cache"}}, resolver.prefetched)
+}
+
+func fixtureResolver(t *testing.T) *fixtures.Resolver {
+	t.Helper()
+	resolver, err := fixtures.Load(filepath.Join("..", "..", "snapshots", "fixtures.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolver
+}
+
+// writeWorkflow writes a workflow with a single job that has a step for each uses
+// value to a temporary directory, and returns its path.
+func writeWorkflow(t *testing.T, uses ...string) string {
+	t.Helper()
+	content := "jobs:\n  build:\n    steps:\n"
+	for _, u := range uses {
+		content += "      - uses: " + u + "\n"
+	}
+	path := filepath.Join(t.TempDir(), "workflow.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// copySnapshot copies a file from the snapshots directory to dir, and returns the path of the copy.
+func copySnapshot(t *testing.T, dir string, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("..", "..", "snapshots", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPinAllActionsTagPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		uses     string
+		expected string
+	}{
+		{
+			name:     "tags are described by the action's prefixed versions",
+			uses:     "aws-actions/toolkit/deploy@deploy-v1",
+			expected: "aws-actions/toolkit/deploy@bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb # deploy-v1.3.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeWorkflow(t, tt.uses)
+
+			opts := processor.Options{
+				Write:       true,
+				TagPrefixes: map[string]string{"aws-actions/toolkit/deploy": "deploy-"},
+				Resolver:    fixtureResolver(t),
 			}
+			results := processor.PinAllActions([]string{path}, opts)
+			assert.Equal(t, processor.Summary{Total: 1, Changed: 1}, processor.Summarize(results))
+
+			content, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Contains(t, string(content), tt.expected)
 		})
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor
+
+import (
+	"sync"
+
+	"github.com/behnh/actions-toolkit/internal/file"
+)
+
+// DefaultConcurrency is the number of actions resolved at the same time by default.
+const DefaultConcurrency = 8
+
+// target is a ref of an action to resolve. For SHA refs, the version comment is part
+// of the target, since the current version is taken from it.
+type target struct {
+	action  string
+	ref     string
+	comment string
+}
+
+// resolution is the version and commit SHA that a target resolves to.
+type resolution struct {
+	version string
+	sha     string
+	err     error
+}
+
+// newTarget returns the target for a ref of an action used in u.
+func newTarget(u file.Uses, actionName string, ref string) target {
+	t := target{action: actionName, ref: ref}
+	if len(ref) == 40 && isHexString(ref) {
+		t.comment = u.Comment
+	}
+	return t
+}
+
+// resolveTargets resolves each distinct target with resolve, which is pinTarget or
+// resolveTarget, using up to opts.Concurrency workers. The GitHub package caches what
+// it fetches, so targets of the same repository share their lookups.
+func resolveTargets(opts Options, targets []target, resolve func(Options, string, string, string) (string, string, error)) map[target]resolution {
+	var unique []target
+	seen := make(map[target]bool)
+	for _, t := range targets {
+		if !seen[t] {
+			seen[t] = true
+			unique = append(unique, t)
+		}
+	}
+
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = DefaultConcurrency
+	}
+	workers = min(workers, len(unique))
+
+	resolutions := make([]resolution, len(unique))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				t := unique[i]
+				version, sha, err := resolve(opts, t.action, t.ref, t.comment)
+				resolutions[i] = resolution{version: version, sha: sha, err: err}
+			}
+		}()
+	}
+	for i := range unique {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	resolved := make(map[target]resolution, len(unique))
+	for i, t := range unique {
+		resolved[t] = resolutions[i]
+	}
+	return resolved
+}
+
+// prefetchLatestReleases fetches the latest releases of the targets that resolveTarget
+// resolves to their latest release in batches, rather than one by one. Even a single
+// repository is worth prefetching, since a batch resolves it in one request.
+func prefetchLatestReleases(opts Options, targets []target) {
+	if opts.Lock != nil || opts.MinAge > 0 || opts.AllowPrerelease {
+		return
+	}
+
+	var actions []string
+	for _, t := range targets {
+		if opts.version(t.action) == "" && opts.strategy(t.action) == StrategyMajor && opts.tagPrefix(t.action) == "" {
+			actions = append(actions, t.action)
+		}
+	}
+	if p, ok := opts.resolver().(prefetcher); ok && len(actions) > 0 {
+		p.PrefetchLatestReleases(actions)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/behnh/actions-toolkit/internal/file"
+	"github.com/behnh/actions-toolkit/internal/lockfile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveTargets(t *testing.T) {
+	lock := lockfile.New()
+	var targets []target
+	for i := range 20 {
+		action := fmt.Sprintf("org/action-%d", i)
+		lock.Set(action, "v1", lockfile.Entry{Version: "v1.0.0", SHA: fmt.Sprintf("%040d", i)})
+		// Every target appears twice, and is only resolved once
+		targets = append(targets, target{action: action, ref: "v1"}, target{action: action, ref: "v1"})
+	}
+	targets = append(targets, target{action: "org/unknown", ref: "v1"})
+
+	resolved := resolveTargets(Options{Lock: lock, Concurrency: 4}, targets, pinTarget)
+	assert.Len(t, resolved, 21)
+	assert.Equal(t, resolution{version: "v1.0.0", sha: fmt.Sprintf("%040d", 7)}, resolved[target{action: "org/action-7", ref: "v1"}])
+	assert.Error(t, resolved[target{action: "org/unknown", ref: "v1"}].err)
+}
+
+func TestNewTarget(t *testing.T) {
+	sha := "11bd71901bbe5b1630ceea73d27597364c9af683"
+	u := file.Uses{Value: "actions/checkout@" + sha, Comment: "# v4.2.2"}
+	assert.Equal(t, target{action: "actions/checkout", ref: sha, comment: "# v4.2.2"}, newTarget(u, "actions/checkout", sha))
+
+	// The comment of a tag ref does not affect its resolution
+	u = file.Uses{Value: "actions/checkout@v4", Comment: "# keep"}
+	assert.Equal(t, target{action: "actions/checkout", ref: "v4"}, newTarget(u, "actions/checkout", "v4"))
+}
+
+func TestPinAllActionsOrder(t *testing.T) {
+	lock := lockfile.New()
+	var files []string
+	dir := t.TempDir()
+	for i := range 10 {
+		action := fmt.Sprintf("org/action-%d", i)
+		lock.Set(action, "v1", lockfile.Entry{Version: "v1.0.0", SHA: fmt.Sprintf("%040d", i)})
+		path := filepath.Join(dir, fmt.Sprintf("workflow-%d.yml", i))
+		content := fmt.Sprintf("jobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: %s@v1\n      - uses: org/action-0@v1\n", action)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		files = append(files, path)
+	}
+
+	first := PinAllActions(files, Options{Lock: lock, Concurrency: 8})
+	assert.Len(t, first, 20)
+	for i, r := range first {
+		assert.Equal(t, files[i/2], r.File)
+		assert.Equal(t, StatusChanged, r.Status)
+	}
+	for range 5 {
+		assert.Equal(t, first, PinAllActions(files, Options{Lock: lock, Concurrency: 8}))
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor
+
+import (
+	"github.com/behnh/actions-toolkit/internal/github"
+	"github.com/behnh/actions-toolkit/internal/registry"
+)
+
+// Resolver looks up the releases, tags and commits of actions and the digests of
+// container images. The processor only resolves values through the resolver in its
+// options, so that it can run against something other than GitHub, such as fixtures
+// in tests. Action names are in the format "org/repo/optional_subpath".
+type Resolver interface {
+	// LatestRelease returns the latest release of an action and the commit SHA it
+	// points at, or an empty version if the action has no releases. With a tag prefix,
+	// only releases whose tags start with it are considered.
+	LatestRelease(actionName string, currentVersion string, tagPrefix string) (string, string, error)
+	// TagSHA returns the commit SHA a tag of an action points at, or an empty SHA if
+	// the tag does not exist.
+	TagSHA(actionName string, tag string) (string, error)
+	// RefSHA returns the commit SHA a tag or branch of an action points at, or an
+	// empty SHA if there is no such tag or branch.
+	RefSHA(actionName string, ref string) (string, error)
+	// Releases returns the published releases of an action, newest first, including
+	// prereleases.
+	Releases(actionName string) ([]github.Release, error)
+	// TagsForCommit returns the tags of an action that point at a commit SHA.
+	TagsForCommit(actionName string, sha string) ([]string, error)
+	// IsCommitInRepository reports whether a commit SHA is reachable from a branch or
+	// tag of the action's repository.
+	IsCommitInRepository(actionName string, sha string) (bool, error)
+	// ImageDigest returns the digest that the tag of a container image points at.
+	ImageDigest(image string) (string, error)
+}
+
+// prefetcher is implemented by resolvers that can look up the latest releases of many
+// actions more efficiently at once than one by one.
+type prefetcher interface {
+	PrefetchLatestReleases(actionNames []string)
+}
+
+// NetworkResolver resolves actions through the GitHub API, and images through their
+// registries. It is the resolver used when Options.Resolver is nil.
+type NetworkResolver struct {
+	Token string // GitHub token for the default instance, resolved from the environment if empty
+}
+
+func (r NetworkResolver) LatestRelease(actionName string, currentVersion string, tagPrefix string) (string, string, error) {
+	if tagPrefix != "" {
+		return github.GetLatestPrefixedReleaseWithSHA(r.Token, actionName, tagPrefix)
+	}
+	return github.GetLatestReleaseWithSHA(r.Token, actionName, currentVersion)
+}
+
+func (r NetworkResolver) TagSHA(actionName string, tag string) (string, error) {
+	return github.GetTagSHA(r.Token, actionName, tag)
+}
+
+func (r NetworkResolver) RefSHA(actionName string, ref string) (string, error) {
+	return github.GetRefSHA(r.Token, actionName, ref)
+}
+
+func (r NetworkResolver) Releases(actionName string) ([]github.Release, error) {
+	return github.ListReleases(r.Token, actionName)
+}
+
+func (r NetworkResolver) TagsForCommit(actionName string, sha string) ([]string, error) {
+	return github.GetTagsForCommit(r.Token, actionName, sha)
+}
+
+func (r NetworkResolver) IsCommitInRepository(actionName string, sha string) (bool, error) {
+	return github.IsCommitInRepository(r.Token, actionName, sha)
+}
+
+func (r NetworkResolver) ImageDigest(image string) (string, error) {
+	return registry.GetDigest(image)
+}
+
+// PrefetchLatestReleases looks up the latest releases of the actions in batches.
+func (r NetworkResolver) PrefetchLatestReleases(actionNames []string) {
+	github.PrefetchLatestReleases(r.Token, actionNames)
+}
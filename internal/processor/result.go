@@ -0,0 +1,141 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/behnh/actions-toolkit/internal/file"
+)
+
+// Status is the outcome of processing a single uses value.
+type Status string
+
+const (
+	// StatusChanged means the value was changed, or would be changed in a dry run
+	StatusChanged Status = "changed"
+	// StatusUnchanged means the value is already pinned or up to date
+	StatusUnchanged Status = "unchanged"
+	// StatusSkipped means the value was not processed (e.g., a branch ref or an action without releases)
+	StatusSkipped Status = "skipped"
+	// StatusError means the value, or the file containing it, could not be processed
+	StatusError Status = "error"
+
+	// StatusOK means a pinned SHA matches its version comment, or is tagged if there is no comment
+	StatusOK Status = "ok"
+	// StatusMismatch means the version comment resolves to a different commit than the pinned SHA
+	StatusMismatch Status = "mismatch"
+	// StatusUntagged means the pinned SHA is in the repository, but no tag points at it
+	StatusUntagged Status = "untagged"
+	// StatusFork means the pinned SHA is not reachable from the repository and most likely comes from a fork
+	StatusFork Status = "fork"
+)
+
+// Result describes what happened to a single uses value during a pin, update or verify run.
+type Result struct {
+	File        string        `json:"file"`
+	Line        int           `json:"line,omitempty"`
+	Column      int           `json:"column,omitempty"`
+	EndColumn   int           `json:"end_column,omitempty"` // Column just past the end of the value
+	Kind        file.UsesKind `json:"kind,omitempty"`
+	Image       bool          `json:"image,omitempty"`        // Whether the value is a container image
+	Action      string        `json:"action,omitempty"`       // owner/repo, or the image name
+	Subpath     string        `json:"subpath,omitempty"`      // Path within the repository (e.g., save in actions/cache/save)
+	OldRef      string        `json:"old_ref,omitempty"`      // The ref before the run
+	NewRef      string        `json:"new_ref,omitempty"`      // The ref after the run, if it changed
+	NewVersion  string        `json:"new_version,omitempty"`  // The version written to the comment
+	Version     string        `json:"version,omitempty"`      // The version from the existing comment
+	ExpectedRef string        `json:"expected_ref,omitempty"` // The SHA that Version resolves to, when verifying
+	Tags        []string      `json:"tags,omitempty"`         // Tags that point at OldRef, when verifying
+	Status      Status        `json:"status"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// Summary counts the outcomes of a run.
+type Summary struct {
+	Total    int `json:"total"`
+	Changed  int `json:"changed"`  // Values that were changed, or would be changed in a dry run
+	Problems int `json:"problems"` // Values that failed verification
+	Failed   int `json:"failed"`   // Values or files that could not be processed
+}
+
+// Summarize counts the outcomes of a set of results.
+func Summarize(results []Result) Summary {
+	summary := Summary{Total: len(results)}
+	for _, r := range results {
+		switch r.Status {
+		case StatusChanged:
+			summary.Changed++
+		case StatusMismatch, StatusUntagged, StatusFork:
+			summary.Problems++
+		case StatusError:
+			summary.Failed++
+		}
+	}
+	return summary
+}
+
+// newResult creates a result for a uses value, splitting it into the action, subpath and ref.
+func newResult(u file.Uses) Result {
+	r := Result{
+		File:      u.File,
+		Line:      u.Line,
+		Column:    u.Column,
+		EndColumn: u.Column + utf8.RuneCountInString(u.Value),
+		Kind:      u.Kind,
+	}
+
+	if u.IsImage() {
+		r.Image = true
+		name, digest, _ := strings.Cut(strings.TrimPrefix(u.Value, file.DockerPrefix), "@")
+		tag := ""
+		if i := strings.LastIndex(name, ":"); i >= 0 && !strings.Contains(name[i+1:], "/") {
+			name, tag = name[:i], name[i+1:]
+		}
+		r.Action = name
+		r.OldRef = strings.Trim(tag+"@"+digest, "@")
+		return r
+	}
+
+	actionName, ref, ok := splitUses(u.Value)
+	if !ok {
+		r.Action = u.Value
+		return r
+	}
+	r.OldRef = ref
+	parts := strings.SplitN(actionName, "/", 3)
+	r.Action = actionName
+	if len(parts) == 3 {
+		r.Action = parts[0] + "/" + parts[1]
+		r.Subpath = parts[2]
+	}
+
+	return r
+}
+
+// fileError creates a result for a file that could not be processed.
+func fileError(filePath string, err error) Result {
+	return Result{File: filePath, Status: StatusError, Error: err.Error()}
+}
+
+// fail marks a result as failed with the given error.
+func (r Result) fail(err error) Result {
+	r.Status = StatusError
+	r.Error = err.Error()
+	return r
+}
@@ -0,0 +1,37 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor
+
+// ScanActions runs the checks of PinAllActions, FindOutdatedPins and VerifyActions on
+// the given files without changing them, reading and parsing each file only once. It
+// returns the results of each check in turn, and a single result for each file that
+// could not be processed.
+func ScanActions(files []string, opts Options) []Result {
+	opts.Write = false
+	workflows := readWorkflowFiles(files)
+	results := pinAllActions(workflows, opts)
+
+	// Files that could not be processed have been reported by the first check
+	var parsed []workflowFile
+	for _, workflow := range workflows {
+		if workflow.err == nil {
+			parsed = append(parsed, workflow)
+		}
+	}
+	results = append(results, findOutdatedPins(parsed, opts)...)
+	return append(results, verifyActions(parsed, opts)...)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/behnh/actions-toolkit/internal/processor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanActions(t *testing.T) {
+	path := writeWorkflow(t,
+		"actions/cache@v4",
+		"actions/setup-node@cdca7365b2dadb8aad0a33bc7601856ffabcc48e # v4.4.0")
+	malformed := filepath.Join(t.TempDir(), "malformed.yml")
+	assert.NoError(t, os.WriteFile(malformed, []byte("jobs: [\n"), 0644))
+	original, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	results := processor.ScanActions([]string{path, malformed}, processor.Options{Write: true, Resolver: fixtureResolver(t)})
+
+	var statuses []processor.Status
+	for _, r := range results {
+		statuses = append(statuses, r.Status)
+	}
+	// Pinning, then the outdated pin, then the mismatched version comment, and the
+	// malformed file only once
+	assert.Equal(t, []processor.Status{
+		processor.StatusChanged, processor.StatusUnchanged, processor.StatusError,
+		processor.StatusChanged,
+		processor.StatusMismatch,
+	}, statuses)
+	assert.Equal(t, malformed, results[2].File)
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(original), string(content))
+}
@@ -0,0 +1,162 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/behnh/actions-toolkit/internal/github"
+	"github.com/behnh/actions-toolkit/internal/semver"
+)
+
+// Strategy controls how far an action may move from its current version.
+type Strategy string
+
+const (
+	// StrategyMajor moves to the latest release, even if it is a new major version
+	StrategyMajor Strategy = "major"
+	// StrategyMinor stays in the current major version, moving to its newest minor or patch release
+	StrategyMinor Strategy = "minor"
+	// StrategyPatch stays in the current minor version, moving to its newest patch release
+	StrategyPatch Strategy = "patch"
+)
+
+// ParseStrategy parses an update strategy, defaulting to StrategyMajor when empty.
+func ParseStrategy(s string) (Strategy, error) {
+	switch strategy := Strategy(s); strategy {
+	case "":
+		return StrategyMajor, nil
+	case StrategyMajor, StrategyMinor, StrategyPatch:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unsupported update strategy %q, expected patch, minor or major", s)
+	}
+}
+
+// ParseMinAge parses a minimum release age such as 7d, 2w or 36h. Days and weeks are
+// supported in addition to the units of time.ParseDuration. An empty string means
+// there is no minimum age.
+func ParseMinAge(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit != 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid minimum age %q, expected e.g. 7d, 2w or 36h", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+
+	age, err := time.ParseDuration(s)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid minimum age %q, expected e.g. 7d, 2w or 36h", s)
+	}
+	return age, nil
+}
+
+// prefixedReleases returns the releases whose tags start with prefix, with the prefix
+// removed from their tags so that they can be compared as versions. It returns all
+// releases if the prefix is empty.
+func prefixedReleases(releases []github.Release, prefix string) []github.Release {
+	if prefix == "" {
+		return releases
+	}
+	var prefixed []github.Release
+	for _, release := range releases {
+		if tag, found := strings.CutPrefix(release.Tag, prefix); found {
+			release.Tag = tag
+			prefixed = append(prefixed, release)
+		}
+	}
+	return prefixed
+}
+
+// eligibleReleases returns the releases that were published at least minAge before
+// now. Releases without a publish date are only eligible if there is no minimum age.
+func eligibleReleases(releases []github.Release, minAge time.Duration, now time.Time) []github.Release {
+	cutoff := now.Add(-minAge)
+	var eligible []github.Release
+	for _, release := range releases {
+		if minAge > 0 && (release.PublishedAt.IsZero() || release.PublishedAt.After(cutoff)) {
+			continue
+		}
+		eligible = append(eligible, release)
+	}
+	return eligible
+}
+
+// selectVersion returns the newest release that the strategy allows relative to the
+// current version, or an empty string if there is none. Releases older than the
+// current version are never selected. A current version without a minor number
+// (e.g., v4) is treated as staying in its major version for the patch strategy.
+//
+// Prereleases, whether marked on GitHub or by a version suffix, are only selected if
+// allowPrerelease is set, or if the current version is a prerelease of the same
+// major, minor and patch version. An action on v5.0.0-beta.1 therefore moves on to
+// v5.0.0-rc.1 and then v5.0.0, but not to v5.1.0-beta.1.
+func selectVersion(releases []github.Release, current semver.Version, strategy Strategy, allowPrerelease bool) string {
+	best, bestTag := semver.Version{}, ""
+	for _, release := range releases {
+		v, ok := semver.Parse(release.Tag)
+		if !ok {
+			continue
+		}
+
+		if (release.Prerelease || v.IsPrerelease()) && !allowPrerelease && !samePrereleaseLine(v, current) {
+			continue
+		}
+
+		switch strategy {
+		case StrategyPatch:
+			if v.Major != current.Major || (current.Parts > 1 && v.Minor != current.Minor) {
+				continue
+			}
+		case StrategyMinor:
+			if v.Major != current.Major {
+				continue
+			}
+		}
+
+		if bestTag == "" || semver.Compare(v, best) > 0 {
+			best, bestTag = v, release.Tag
+		}
+	}
+
+	if bestTag == "" || semver.Compare(best, current) < 0 {
+		return ""
+	}
+	return bestTag
+}
+
+// samePrereleaseLine reports whether current is a prerelease of the same version as v,
+// ignoring any prerelease suffix of v.
+func samePrereleaseLine(v semver.Version, current semver.Version) bool {
+	return current.IsPrerelease() &&
+		v.Major == current.Major && v.Minor == current.Minor && v.Patch == current.Patch
+}
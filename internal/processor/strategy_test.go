@@ -0,0 +1,138 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/behnh/actions-toolkit/internal/github"
+	"github.com/behnh/actions-toolkit/internal/semver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectVersion(t *testing.T) {
+	var releases []github.Release
+	for _, tag := range []string{"v5.0.0-beta.2", "v4.2.0", "v4.1.0", "v4.0.2", "v3.5.0-rc.1", "v3.4.0", "v3.3.3", "v3.3.2", "latest", "v2.9.9"} {
+		releases = append(releases, github.Release{Tag: tag})
+	}
+	// Marked as a prerelease on GitHub, without a prerelease suffix
+	releases[1].Prerelease = true
+
+	tests := []struct {
+		name            string
+		current         string
+		strategy        Strategy
+		allowPrerelease bool
+		expected        string
+	}{
+		{"major moves to the newest release", "v3.3.1", StrategyMajor, false, "v4.1.0"},
+		{"minor stays in the current major", "v3.3.1", StrategyMinor, false, "v3.4.0"},
+		{"patch stays in the current minor", "v3.3.1", StrategyPatch, false, "v3.3.3"},
+		{"patch on a major version tag", "v3", StrategyPatch, false, "v3.4.0"},
+		{"already on the newest allowed release", "v3.4.0", StrategyMinor, false, "v3.4.0"},
+		{"no release in the current major", "v1.2.0", StrategyMinor, false, ""},
+		{"never downgrades", "v4.2.0", StrategyMajor, false, ""},
+		{"versions without a v prefix", "3.3.1", StrategyPatch, false, "v3.3.3"},
+		{"major with prereleases allowed", "v3.3.1", StrategyMajor, true, "v5.0.0-beta.2"},
+		{"minor with prereleases allowed", "v3.3.1", StrategyMinor, true, "v3.5.0-rc.1"},
+		{"newer prerelease of the current prerelease", "v5.0.0-beta.1", StrategyMajor, false, "v5.0.0-beta.2"},
+		{"prerelease moves on within its version", "v3.5.0-beta.1", StrategyMinor, false, "v3.5.0-rc.1"},
+		{"prerelease moves on to a release marked as prerelease", "v4.2.0-beta.1", StrategyMajor, false, "v4.2.0"},
+		{"prerelease does not move to other prereleases", "v4.1.0-rc.1", StrategyMajor, false, "v4.1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, ok := semver.Parse(tt.current)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, selectVersion(releases, current, tt.strategy, tt.allowPrerelease))
+		})
+	}
+}
+
+func TestParseStrategy(t *testing.T) {
+	strategy, err := ParseStrategy("")
+	assert.NoError(t, err)
+	assert.Equal(t, StrategyMajor, strategy)
+
+	strategy, err = ParseStrategy("patch")
+	assert.NoError(t, err)
+	assert.Equal(t, StrategyPatch, strategy)
+
+	_, err = ParseStrategy("latest")
+	assert.Error(t, err)
+}
+
+func TestOptionsStrategy(t *testing.T) {
+	opts := Options{
+		Strategy:   StrategyMinor,
+		Strategies: map[string]Strategy{"actions/cache": StrategyPatch},
+	}
+
+	assert.Equal(t, StrategyPatch, opts.strategy("actions/cache"))
+	assert.Equal(t, StrategyPatch, opts.strategy("actions/cache/save"))
+	assert.Equal(t, StrategyMinor, opts.strategy("actions/checkout"))
+	assert.Equal(t, StrategyMajor, Options{}.strategy("actions/checkout"))
+}
+
+func TestParseMinAge(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"", 0, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"0d", 0, false},
+		{"d", 0, true},
+		{"-1d", 0, true},
+		{"7 days", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMinAge(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestEligibleReleases(t *testing.T) {
+	fixed := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+
+	releases := []github.Release{
+		{Tag: "v4.1.0", PublishedAt: fixed.Add(-2 * 24 * time.Hour)},
+		{Tag: "v4.0.2", PublishedAt: fixed.Add(-10 * 24 * time.Hour)},
+		{Tag: "v4.0.1"},
+		{Tag: "v3.4.0", PublishedAt: fixed.Add(-100 * 24 * time.Hour)},
+	}
+
+	assert.Equal(t, releases, eligibleReleases(releases, 0, fixed))
+	assert.Equal(t, []github.Release{releases[1], releases[3]}, eligibleReleases(releases, 7*24*time.Hour, fixed))
+
+	current, _ := semver.Parse("v3.4.0")
+	assert.Equal(t, "v4.0.2", selectVersion(eligibleReleases(releases, 7*24*time.Hour, fixed), current, StrategyMajor, false))
+	assert.Equal(t, "", selectVersion(eligibleReleases(releases, 365*24*time.Hour, fixed), current, StrategyMajor, false))
+}
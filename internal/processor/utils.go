@@ -19,6 +19,9 @@ package processor
 import (
 	"regexp"
 	"strings"
+
+	"github.com/behnh/actions-toolkit/internal/file"
+	"github.com/behnh/actions-toolkit/internal/semver"
 )
 
 func isHexString(s string) bool {
@@ -26,7 +29,7 @@ func isHexString(s string) bool {
 	return match
 }
 
-func updateVersionComment(line string, version string) string {
+func updateVersionComment(line string, version string, tagPrefix string) string {
 	parts := strings.SplitN(line, "#", 2)
 	baseContent := strings.TrimRight(parts[0], " ")
 
@@ -41,8 +44,8 @@ func updateVersionComment(line string, version string) string {
 
 	// Check for different comment patterns
 	if len(commentParts) > 0 {
-		// Case 1: Comment starts with a version number (v4.3.0 or 4.3.0)
-  if strings.HasPrefix(commentParts[0], "v") || IsVersionNumber(commentParts[0]) {
+		// Case 1: Comment starts with a version number (v4.3.0, 4.3.0, or sub-v4.3.0 with a tag prefix)
+		if strings.HasPrefix(commentParts[0], "v") || IsVersionNumber(commentParts[0]) || isPrefixedVersion(commentParts[0], tagPrefix) {
 			// Replace the version part, keep any additional text
 			if len(commentParts) > 1 {
 				newComment := version + " " + strings.Join(commentParts[1:], " ")
@@ -74,7 +77,7 @@ func updateVersionComment(line string, version string) string {
 			// Check if there's already a version-looking string anywhere in the comment
 			foundVersion := false
 			for i, part := range commentParts {
-    if (strings.HasPrefix(part, "v") && IsVersionNumber(part[1:])) || IsVersionNumber(part) {
+				if (strings.HasPrefix(part, "v") && IsVersionNumber(part[1:])) || IsVersionNumber(part) {
 					// Replace this part with the new version
 					commentParts[i] = version
 					foundVersion = true
@@ -95,12 +98,60 @@ func updateVersionComment(line string, version string) string {
 	}
 }
 
+// extractCommentVersion returns the version recorded in a version comment, using the
+// same patterns that updateVersionComment understands: a leading version (v4.3.0 or
+// 4.3.0), a pin@version prefix, or a version anywhere in the comment. Versions with the
+// tag prefix of an action in a monorepo (e.g., sub-v4.3.0) are only recognized at the
+// start of the comment. It returns an empty string if the comment has no version.
+func extractCommentVersion(comment string, tagPrefix string) string {
+	commentParts := strings.Fields(strings.TrimPrefix(strings.TrimSpace(comment), "#"))
+	if len(commentParts) == 0 {
+		return ""
+	}
+
+	// Case 1: Comment starts with a version number
+	if looksLikeVersion(commentParts[0]) || isPrefixedVersion(commentParts[0], tagPrefix) {
+		return commentParts[0]
+	}
+
+	// Case 2: Comment contains a pin@v4 pattern
+	if pinParts := strings.Split(commentParts[0], "@"); len(pinParts) == 2 && (looksLikeVersion(pinParts[1]) || isPrefixedVersion(pinParts[1], tagPrefix)) {
+		return pinParts[1]
+	}
+
+	// Case 3: A version-looking string anywhere in the comment
+	for _, part := range commentParts {
+		if looksLikeVersion(part) {
+			return part
+		}
+	}
+
+	return ""
+}
+
+// looksLikeVersion checks if a string is a version number (e.g., v4.3.0, 4.3 or
+// v5.0.0-rc.1) or a major version tag (e.g., v4).
+func looksLikeVersion(s string) bool {
+	v, ok := semver.Parse(s)
+	return ok && (v.Parts > 1 || v.Prefix)
+}
+
+// isPrefixedVersion checks if a string is a version with the tag prefix of an action in
+// a monorepo (e.g., sub-v1.2.0 for sub-). It is always false without a prefix.
+func isPrefixedVersion(s string, tagPrefix string) bool {
+	version, found := strings.CutPrefix(s, tagPrefix)
+	return tagPrefix != "" && found && looksLikeVersion(version)
+}
+
 // isMajorVersionConstraint checks if a version string is a major version constraint,
-// For example, "v4" or "4" are major version constraints, but "v4.3.0" is not
+// For example, "v4" or "4" are major version constraints, but "v4.3.0" and "v5-beta" are not
 func isMajorVersionConstraint(version string) bool {
 	if version == "" {
 		return false
 	}
+	if v, ok := semver.Parse(version); ok {
+		return v.Parts == 1 && !v.IsPrerelease()
+	}
 
 	// Remove the 'v' prefix if it exists
 	versionStr := version
@@ -112,12 +163,21 @@ func isMajorVersionConstraint(version string) bool {
 	return !strings.Contains(versionStr, ".")
 }
 
+// isPrerelease checks if a version has a prerelease suffix (e.g., v5.0.0-beta.1)
+func isPrerelease(version string) bool {
+	v, ok := semver.Parse(version)
+	return ok && v.IsPrerelease()
+}
+
 // extractMajorVersion extracts the major version from a full version string
-// For example, "v3.5.0" -> "v3", "v1.2.3" -> "v1"
+// For example, "v3.5.0" -> "v3", "v1.2.3" -> "v1", "v5.0.0-beta.1" -> "v5"
 func extractMajorVersion(version string) string {
 	if version == "" {
 		return ""
 	}
+	if v, ok := semver.Parse(version); ok {
+		return v.MajorTag()
+	}
 
 	// Remove the 'v' prefix if it exists
 	versionStr := version
@@ -138,3 +198,45 @@ func extractMajorVersion(version string) string {
 
 	return parts[0]
 }
+
+// splitUses splits a uses value such as "actions/cache/save@v4" into the action
+// name and the ref. ok is false if the value does not reference a single ref.
+func splitUses(uses string) (action string, ref string, ok bool) {
+	parts := strings.Split(uses, "@")
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return strings.TrimSpace(parts[0]), parts[1], true
+}
+
+// rewriteUses returns the edits needed to replace the value of u with newValue and,
+// if version is not empty, to update the version comment that follows it. An existing
+// version with tagPrefix is replaced like any other version, and a missing comment is
+// added in the given style. Only the value itself and the remainder of its line are
+// ever touched.
+func rewriteUses(content []byte, u file.Uses, newValue string, version string, tagPrefix string, style CommentStyle) []file.Edit {
+	edits := []file.Edit{{Start: u.Start, End: u.End, Text: newValue}}
+	if version == "" {
+		return edits
+	}
+
+	// The rest of the line may only hold a closing quote and a comment, otherwise
+	// the value is part of a flow mapping and its comment is left alone.
+	lineEnd := file.LineEnd(content, u.End)
+	rest := string(content[u.End:lineEnd])
+	beforeComment := strings.SplitN(rest, "#", 2)[0]
+	if strings.Trim(beforeComment, ` "'`) != "" {
+		return edits
+	}
+
+	if !strings.Contains(rest, "#") {
+		switch style {
+		case CommentNone:
+			return edits
+		case CommentPin:
+			version = "pin@" + version
+		}
+	}
+
+	return append(edits, file.Edit{Start: u.End, End: lineEnd, Text: updateVersionComment(rest, version, tagPrefix)})
+}
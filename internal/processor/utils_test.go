@@ -3,6 +3,7 @@ package processor
 import (
 	"testing"
 
+	"github.com/behnh/actions-toolkit/internal/file"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -62,6 +63,7 @@ func TestUpdateVersionComment(t *testing.T) {
 		name           string
 		line           string
 		version        string
+		tagPrefix      string
 		expectedOutput string
 	}{
 		{
@@ -112,11 +114,24 @@ func TestUpdateVersionComment(t *testing.T) {
 			version:        "v4.4.0",
 			expectedOutput: "uses: actions/setup-node@v4.3.0 # v4.4.0",
 		},
+		{
+			name:           "comment with prefixed version",
+			line:           "uses: aws-actions/toolkit/deploy@abc # deploy-v1.2.0 pinned",
+			version:        "deploy-v1.3.0",
+			tagPrefix:      "deploy-",
+			expectedOutput: "uses: aws-actions/toolkit/deploy@abc # deploy-v1.3.0 pinned",
+		},
+		{
+			name:           "comment with prefixed version without tag prefix",
+			line:           "uses: actions/setup-node@v4.3.0 # node-v20 required",
+			version:        "v4.4.0",
+			expectedOutput: "uses: actions/setup-node@v4.3.0 # v4.4.0 node-v20 required",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := updateVersionComment(tt.line, tt.version)
+			result := updateVersionComment(tt.line, tt.version, tt.tagPrefix)
 			assert.Equal(t, tt.expectedOutput, result)
 		})
 	}
@@ -223,3 +238,144 @@ func TestExtractMajorVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestRewriteUses(t *testing.T) {
+	content := []byte(`jobs:
+  test:
+    steps:
+      # Pinned from actions/cache@v3
+      - uses: actions/cache@v3 # v3.0.0 keep
+      - run: echo "actions/cache@v3"
+      - uses: "actions/cache@v3"
+      - { uses: actions/cache@v3, with: { key: a } } # flow
+`)
+
+	uses, err := file.ParseUses("workflow.yaml", content)
+	assert.NoError(t, err)
+	assert.Len(t, uses, 3)
+
+	var edits []file.Edit
+	for _, u := range uses {
+		edits = append(edits, rewriteUses(content, u, "actions/cache@abc", "v3.1.0", "", CommentVersion)...)
+	}
+
+	got, err := file.ApplyEdits(content, edits)
+	assert.NoError(t, err)
+	assert.Equal(t, `jobs:
+  test:
+    steps:
+      # Pinned from actions/cache@v3
+      - uses: actions/cache@abc # v3.1.0 keep
+      - run: echo "actions/cache@v3"
+      - uses: "actions/cache@abc" # v3.1.0
+      - { uses: actions/cache@abc, with: { key: a } } # flow
+`, string(got))
+}
+
+func TestRewriteUsesCommentStyle(t *testing.T) {
+	content := []byte(`runs:
+  steps:
+    - uses: actions/cache@v3
+    - uses: actions/cache@v3 # v3.0.0
+`)
+
+	uses, err := file.ParseUses("action.yaml", content)
+	assert.NoError(t, err)
+	assert.Len(t, uses, 2)
+
+	tests := []struct {
+		style    CommentStyle
+		expected string
+	}{
+		{CommentVersion, "runs:\n  steps:\n    - uses: actions/cache@abc # v3.1.0\n    - uses: actions/cache@abc # v3.1.0\n"},
+		{CommentPin, "runs:\n  steps:\n    - uses: actions/cache@abc # pin@v3.1.0\n    - uses: actions/cache@abc # v3.1.0\n"},
+		{CommentNone, "runs:\n  steps:\n    - uses: actions/cache@abc\n    - uses: actions/cache@abc # v3.1.0\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			var edits []file.Edit
+			for _, u := range uses {
+				edits = append(edits, rewriteUses(content, u, "actions/cache@abc", "v3.1.0", "", tt.style)...)
+			}
+			got, err := file.ApplyEdits(content, edits)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(got))
+		})
+	}
+}
+
+func TestExtractCommentVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		comment   string
+		tagPrefix string
+		expected  string
+	}{
+		{
+			name:     "version",
+			comment:  "v4.3.0",
+			expected: "v4.3.0",
+		},
+		{
+			name:     "version without v prefix",
+			comment:  "4.3.0 pinned version",
+			expected: "4.3.0",
+		},
+		{
+			name:     "major version",
+			comment:  "v4",
+			expected: "v4",
+		},
+		{
+			name:     "pin@version pattern",
+			comment:  "pin@v4.3.0 stable version",
+			expected: "v4.3.0",
+		},
+		{
+			name:     "version elsewhere",
+			comment:  "stable version v4.3.0",
+			expected: "v4.3.0",
+		},
+		{
+			name:      "prefixed version",
+			comment:   "deploy-v1.2.0",
+			tagPrefix: "deploy-",
+			expected:  "deploy-v1.2.0",
+		},
+		{
+			name:      "prefixed pin@version pattern",
+			comment:   "pin@deploy-v1.2.0",
+			tagPrefix: "deploy-",
+			expected:  "deploy-v1.2.0",
+		},
+		{
+			name:     "prefixed version without tag prefix",
+			comment:  "node-v20 required",
+			expected: "",
+		},
+		{
+			name:      "other prefixed version",
+			comment:   "build-v2.0.0",
+			tagPrefix: "deploy-",
+			expected:  "",
+		},
+		{
+			name:     "no version",
+			comment:  "very stable",
+			expected: "",
+		},
+		{
+			name:     "empty comment",
+			comment:  "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := extractCommentVersion(tt.comment, tt.tagPrefix)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor
+
+import (
+	"log/slog"
+
+	"github.com/behnh/actions-toolkit/internal/file"
+)
+
+// VerifyActions checks every SHA-pinned action in the given files: the version in its
+// comment must resolve to the same commit, and the commit must belong to a tag of the
+// action's own repository rather than a fork. Ignored actions are not checked. It
+// returns a result for each SHA-pinned action, or for each file that could not be processed.
+func VerifyActions(files []string, opts Options) []Result {
+	return verifyActions(readWorkflowFiles(files), opts)
+}
+
+// verifyActions is VerifyActions for files that have already been read and parsed.
+func verifyActions(workflows []workflowFile, opts Options) []Result {
+	var results []Result
+
+	for _, workflow := range workflows {
+		f := workflow.path
+		if workflow.err != nil {
+			results = append(results, fileError(f, workflow.err))
+			continue
+		}
+
+		for _, u := range workflow.uses {
+			if u.IsImage() {
+				continue
+			}
+			actionName, currentVersion, ok := splitUses(u.Value)
+			if !ok || !(len(currentVersion) == 40 && isHexString(currentVersion)) || opts.ignored(actionName) {
+				continue
+			}
+
+			r := verifyAction(opts, u, actionName, currentVersion)
+			results = append(results, r)
+
+			switch r.Status {
+			case StatusOK:
+				slog.Info("Verified pinned action", "action", actionName, "sha", r.OldRef, "version", r.Version, "file", f, "line", u.Line)
+			case StatusMismatch:
+				slog.Warn("Version comment does not match pinned SHA",
+					"action", actionName,
+					"sha", r.OldRef,
+					"version", r.Version,
+					"versionSHA", r.ExpectedRef,
+					"tags", r.Tags,
+					"file", f,
+					"line", u.Line)
+			case StatusUntagged:
+				slog.Warn("Pinned SHA is not tagged in the action's repository", "action", actionName, "sha", r.OldRef, "file", f, "line", u.Line)
+			case StatusFork:
+				slog.Error("Pinned SHA is not reachable from the action's repository and may come from a fork",
+					"action", actionName,
+					"sha", r.OldRef,
+					"file", f,
+					"line", u.Line)
+			case StatusError:
+				slog.Error("Failed to verify action", "action", actionName, "sha", r.OldRef, "file", f, "error", r.Error)
+			}
+		}
+	}
+
+	return results
+}
+
+func verifyAction(opts Options, u file.Uses, actionName, sha string) Result {
+	r := newResult(u)
+	r.Version = opts.commentVersion(actionName, u.Comment)
+	if opts.Lock != nil {
+		return verifyLocked(opts.Lock, r, actionName, sha)
+	}
+	resolver := opts.resolver()
+
+	// The common case only needs a single lookup
+	if r.Version != "" {
+		expected, err := resolver.TagSHA(actionName, r.Version)
+		if err != nil {
+			return r.fail(err)
+		}
+		r.ExpectedRef = expected
+		if expected == sha {
+			r.Tags = []string{r.Version}
+			r.Status = StatusOK
+			return r
+		}
+	}
+
+	tags, err := resolver.TagsForCommit(actionName, sha)
+	if err != nil {
+		return r.fail(err)
+	}
+	r.Tags = tags
+
+	if len(tags) == 0 {
+		inRepository, err := resolver.IsCommitInRepository(actionName, sha)
+		if err != nil {
+			return r.fail(err)
+		}
+		if !inRepository {
+			r.Status = StatusFork
+			return r
+		}
+	}
+
+	switch {
+	case r.Version != "":
+		r.Status = StatusMismatch
+	case len(tags) == 0:
+		r.Status = StatusUntagged
+	default:
+		r.Status = StatusOK
+	}
+
+	return r
+}
@@ -0,0 +1,325 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	dockerHub        = "docker.io"
+	dockerHubAPIHost = "registry-1.docker.io"
+)
+
+// manifestMediaTypes are the manifest formats we accept, with multi-platform indexes
+// first so that the digest matches what `docker pull image:tag` resolves to.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Reference is a parsed container image reference such as ghcr.io/org/app:1.0.
+type Reference struct {
+	Registry   string // Registry host (e.g., docker.io, ghcr.io, localhost:5000)
+	Repository string // Repository within the registry (e.g., library/alpine)
+	Tag        string // Tag, empty if not given
+	Digest     string // Digest, empty if not given
+}
+
+var digestCache = make(map[string]string)
+var cacheMutex sync.RWMutex
+
+var defaultClient = NewClient(nil)
+
+// GetDigest resolves an image reference such as alpine:3.19 to the digest of its
+// manifest (e.g., sha256:...), using anonymous access to the registry.
+func GetDigest(image string) (string, error) {
+	cacheMutex.RLock()
+	if digest, found := digestCache[image]; found {
+		cacheMutex.RUnlock()
+		slog.Debug("Using cached image digest", "image", image, "digest", digest)
+		return digest, nil
+	}
+	cacheMutex.RUnlock()
+
+	ref, err := ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+
+	digest, err := defaultClient.Digest(context.Background(), ref)
+	if err != nil {
+		return "", err
+	}
+
+	cacheMutex.Lock()
+	digestCache[image] = digest
+	cacheMutex.Unlock()
+
+	return digest, nil
+}
+
+// ParseReference parses an image reference using the same defaults as docker:
+// images without a registry host come from Docker Hub, and single-name Docker Hub
+// images live under library/.
+func ParseReference(image string) (Reference, error) {
+	var ref Reference
+	name := image
+
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+	}
+
+	// A tag follows the last colon, as long as it is not part of the registry host
+	if i := strings.LastIndex(name, ":"); i >= 0 && !strings.Contains(name[i+1:], "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+
+	if name == "" {
+		return Reference{}, fmt.Errorf("invalid image reference %q", image)
+	}
+
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.Registry = parts[0]
+		ref.Repository = parts[1]
+	} else {
+		ref.Registry = dockerHub
+		ref.Repository = name
+	}
+
+	if ref.Registry == dockerHub && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+
+	return ref, nil
+}
+
+// Client talks to OCI registries using the Registry HTTP API v2.
+type Client struct {
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]string // Bearer tokens by registry and repository
+}
+
+// NewClient creates a registry client. A nil httpClient uses http.DefaultClient.
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{httpClient: httpClient, tokens: make(map[string]string)}
+}
+
+// Digest returns the manifest digest that ref's tag currently points to.
+func (c *Client) Digest(ctx context.Context, ref Reference) (string, error) {
+	tag := ref.Tag
+	if tag == "" {
+		tag = "latest"
+	}
+	manifestURL := fmt.Sprintf("%s/v2/%s/manifests/%s", baseURL(ref.Registry), ref.Repository, tag)
+
+	// HEAD is enough for registries that send Docker-Content-Digest, and does not
+	// count against Docker Hub's pull limit.
+	resp, err := c.do(ctx, http.MethodHead, manifestURL, ref)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if err := checkResponse(resp, ref, tag); err != nil {
+		return "", err
+	}
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	// Fall back to hashing the manifest ourselves
+	resp, err = c.do(ctx, http.MethodGet, manifestURL, ref)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp, ref, tag); err != nil {
+		return "", err
+	}
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(body)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// do sends a manifest request, fetching a bearer token and retrying once if the
+// registry asks for one.
+func (c *Client) do(ctx context.Context, method, manifestURL string, ref Reference) (*http.Response, error) {
+	tokenKey := ref.Registry + "/" + ref.Repository
+
+	send := func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, manifestURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+		c.mu.Lock()
+		token := c.tokens[tokenKey]
+		c.mu.Unlock()
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return c.httpClient.Do(req)
+	}
+
+	resp, err := send()
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	resp.Body.Close()
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	token, err := c.fetchToken(ctx, challenge, ref)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.tokens[tokenKey] = token
+	c.mu.Unlock()
+
+	return send()
+}
+
+// fetchToken requests an anonymous pull token as described by a Bearer challenge,
+// e.g. Bearer realm="https://auth.docker.io/token",service="registry.docker.io".
+func (c *Client) fetchToken(ctx context.Context, challenge string, ref Reference) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("registry %s requires unsupported authentication %q", ref.Registry, scheme)
+	}
+
+	fields := parseChallenge(params)
+	realm := fields["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("registry %s sent a challenge without a realm", ref.Registry)
+	}
+
+	query := url.Values{}
+	if service := fields["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := fields["scope"]
+	if scope == "" {
+		scope = "repository:" + ref.Repository + ":pull"
+	}
+	query.Set("scope", scope)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get token for %s/%s: %s", ref.Registry, ref.Repository, resp.Status)
+	}
+
+	var tokenResp struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", err
+	}
+	if tokenResp.Token != "" {
+		return tokenResp.Token, nil
+	}
+	return tokenResp.AccessToken, nil
+}
+
+// parseChallenge parses the comma separated key="value" pairs of a challenge.
+func parseChallenge(params string) map[string]string {
+	fields := make(map[string]string)
+	for params != "" {
+		key, rest, ok := strings.Cut(params, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		fields[key] = value
+		params = strings.TrimLeft(rest, ", ")
+	}
+	return fields
+}
+
+func checkResponse(resp *http.Response, ref Reference, tag string) error {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("image %s/%s:%s not found", ref.Registry, ref.Repository, tag)
+	default:
+		return fmt.Errorf("registry %s returned %s", ref.Registry, resp.Status)
+	}
+}
+
+// baseURL returns the API base URL for a registry host. Local registries are
+// reached over plain HTTP, the same as docker does by default.
+func baseURL(registry string) string {
+	if registry == dockerHub {
+		return "https://" + dockerHubAPIHost
+	}
+	host := registry
+	if h, _, err := net.SplitHostPort(registry); err == nil {
+		host = h
+	}
+	if host == "localhost" || net.ParseIP(host).IsLoopback() {
+		return "http://" + registry
+	}
+	return "https://" + registry
+}
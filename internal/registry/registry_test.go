@@ -0,0 +1,126 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		name     string
+		image    string
+		expected Reference
+	}{
+		{
+			name:     "official image with tag",
+			image:    "alpine:3.19",
+			expected: Reference{Registry: "docker.io", Repository: "library/alpine", Tag: "3.19"},
+		},
+		{
+			name:     "user image without tag",
+			image:    "bitnami/redis",
+			expected: Reference{Registry: "docker.io", Repository: "bitnami/redis"},
+		},
+		{
+			name:     "registry host",
+			image:    "ghcr.io/org/app:1.0",
+			expected: Reference{Registry: "ghcr.io", Repository: "org/app", Tag: "1.0"},
+		},
+		{
+			name:     "registry host with port",
+			image:    "localhost:5000/app",
+			expected: Reference{Registry: "localhost:5000", Repository: "app"},
+		},
+		{
+			name:  "tag and digest",
+			image: "node:18@sha256:abc",
+			expected: Reference{
+				Registry:   "docker.io",
+				Repository: "library/node",
+				Tag:        "18",
+				Digest:     "sha256:abc",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := ParseReference(tt.image)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, ref)
+		})
+	}
+}
+
+func TestDigest(t *testing.T) {
+	manifest := []byte(`{"schemaVersion": 2}`)
+	sum := sha256.Sum256(manifest)
+	manifestDigest := "sha256:" + hex.EncodeToString(sum[:])
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			assert.Equal(t, "repository:team/app:pull", r.URL.Query().Get("scope"))
+			w.Write([]byte(`{"token": "pull-token"}`))
+		case r.Header.Get("Authorization") != "Bearer pull-token":
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v2/team/app/manifests/1.0":
+			assert.Contains(t, r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json")
+			w.Header().Set("Docker-Content-Digest", "sha256:1234")
+		case r.URL.Path == "/v2/team/app/manifests/no-header":
+			// Registries that do not send a digest header are hashed locally
+			w.Write(manifest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	client := NewClient(server.Client())
+
+	digest, err := client.Digest(context.Background(), Reference{Registry: host, Repository: "team/app", Tag: "1.0"})
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:1234", digest)
+
+	digest, err = client.Digest(context.Background(), Reference{Registry: host, Repository: "team/app", Tag: "no-header"})
+	assert.NoError(t, err)
+	assert.Equal(t, manifestDigest, digest)
+
+	_, err = client.Digest(context.Background(), Reference{Registry: host, Repository: "team/app", Tag: "missing"})
+	assert.Error(t, err)
+}
+
+func TestParseChallenge(t *testing.T) {
+	fields := parseChallenge(`realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/alpine:pull"`)
+	assert.Equal(t, map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/alpine:pull",
+	}, fields)
+}
@@ -0,0 +1,46 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/behnh/actions-toolkit/internal/processor"
+)
+
+// Report is the machine-readable output of a pin, update or verify run.
+type Report struct {
+	Command string             `json:"command"`
+	Summary processor.Summary  `json:"summary"`
+	Results []processor.Result `json:"results"`
+}
+
+// WriteJSON writes the results of a run as an indented JSON report.
+func WriteJSON(w io.Writer, command string, results []processor.Result) error {
+	if results == nil {
+		results = []processor.Result{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(Report{
+		Command: command,
+		Summary: processor.Summarize(results),
+		Results: results,
+	})
+}
@@ -0,0 +1,85 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/behnh/actions-toolkit/internal/processor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteJSON(t *testing.T) {
+	results := []processor.Result{
+		{
+			File:       ".github/workflows/ci.yaml",
+			Line:       12,
+			Column:     15,
+			Kind:       "step",
+			Action:     "actions/cache",
+			Subpath:    "save",
+			OldRef:     "v4.2.0",
+			NewRef:     "d4323d4df104b026a6aa633fdb11d772146be0bf",
+			NewVersion: "v4.2.2",
+			Status:     processor.StatusChanged,
+		},
+		{
+			File:   ".github/workflows/broken.yaml",
+			Status: processor.StatusError,
+			Error:  "yaml: line 3: mapping values are not allowed in this context",
+		},
+	}
+
+	var buf bytes.Buffer
+	err := WriteJSON(&buf, "pin", results)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"command": "pin",
+		"summary": {"total": 2, "changed": 1, "problems": 0, "failed": 1},
+		"results": [
+			{
+				"file": ".github/workflows/ci.yaml",
+				"line": 12,
+				"column": 15,
+				"kind": "step",
+				"action": "actions/cache",
+				"subpath": "save",
+				"old_ref": "v4.2.0",
+				"new_ref": "d4323d4df104b026a6aa633fdb11d772146be0bf",
+				"new_version": "v4.2.2",
+				"status": "changed"
+			},
+			{
+				"file": ".github/workflows/broken.yaml",
+				"status": "error",
+				"error": "yaml: line 3: mapping values are not allowed in this context"
+			}
+		]
+	}`, buf.String())
+}
+
+func TestWriteJSONNoResults(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteJSON(&buf, "verify", nil)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"command": "verify",
+		"summary": {"total": 0, "changed": 0, "problems": 0, "failed": 0},
+		"results": []
+	}`, buf.String())
+}
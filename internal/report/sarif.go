@@ -0,0 +1,316 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/behnh/actions-toolkit/internal/processor"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "actions-toolkit"
+	toolURI      = "https://github.com/BehnH/actions-toolkit"
+)
+
+// Rule IDs reported in SARIF output
+const (
+	RuleUnpinnedAction  = "unpinned-action"
+	RuleUnpinnedImage   = "unpinned-image"
+	RuleOutdatedPin     = "outdated-pin"
+	RuleCommentMismatch = "version-comment-mismatch"
+	RuleUntaggedCommit  = "untagged-commit"
+	RuleForkCommit      = "fork-commit"
+)
+
+// rules describes every rule that can be reported, in the order they appear in the
+// tool's rule list.
+var rules = []sarifRule{
+	{
+		ID:               RuleUnpinnedAction,
+		Name:             "ActionNotPinnedToSHA",
+		ShortDescription: sarifMessage{Text: "Action is not pinned to a commit SHA"},
+		FullDescription: sarifMessage{Text: "Tags and branches can be moved to point at different code. " +
+			"Pinning an action or reusable workflow to a full commit SHA makes sure the code that runs is the code that was reviewed."},
+		DefaultConfiguration: sarifConfiguration{Level: "warning"},
+	},
+	{
+		ID:                   RuleUnpinnedImage,
+		Name:                 "ImageNotPinnedToDigest",
+		ShortDescription:     sarifMessage{Text: "Container image is not pinned to a digest"},
+		FullDescription:      sarifMessage{Text: "Image tags can be pushed again at any time. Pinning an image to its digest makes sure the same image is used on every run."},
+		DefaultConfiguration: sarifConfiguration{Level: "warning"},
+	},
+	{
+		ID:                   RuleOutdatedPin,
+		Name:                 "PinnedSHAOutOfDate",
+		ShortDescription:     sarifMessage{Text: "Pinned commit SHA is out of date"},
+		FullDescription:      sarifMessage{Text: "The action is pinned to a commit SHA, but a newer release is available."},
+		DefaultConfiguration: sarifConfiguration{Level: "note"},
+	},
+	{
+		ID:                   RuleCommentMismatch,
+		Name:                 "VersionCommentMismatch",
+		ShortDescription:     sarifMessage{Text: "Version comment does not match the pinned commit SHA"},
+		FullDescription:      sarifMessage{Text: "The version in the comment after the pinned SHA resolves to a different commit, so the comment misrepresents the code that runs."},
+		DefaultConfiguration: sarifConfiguration{Level: "error"},
+	},
+	{
+		ID:                   RuleUntaggedCommit,
+		Name:                 "PinnedSHAUntagged",
+		ShortDescription:     sarifMessage{Text: "Pinned commit SHA is not tagged"},
+		FullDescription:      sarifMessage{Text: "No tag in the action's repository points at the pinned commit, so it does not correspond to a release."},
+		DefaultConfiguration: sarifConfiguration{Level: "warning"},
+	},
+	{
+		ID:                   RuleForkCommit,
+		Name:                 "PinnedSHANotInRepository",
+		ShortDescription:     sarifMessage{Text: "Pinned commit SHA is not part of the action's repository"},
+		FullDescription:      sarifMessage{Text: "The pinned commit is not reachable from any branch or tag of the action's repository. It most likely comes from a fork, which GitHub still serves under the original repository's name."},
+		DefaultConfiguration: sarifConfiguration{Level: "error"},
+	},
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	FullDescription      sarifMessage       `json:"fullDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// WriteSARIF writes the findings of a run as a SARIF 2.1.0 log, for upload to GitHub
+// code scanning. Results that are not findings (e.g., values that are already pinned)
+// are left out, and results that could not be processed are reported as tool
+// execution notifications.
+func WriteSARIF(w io.Writer, toolVersion string, results []processor.Result) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           toolName,
+			Version:        toolVersion,
+			InformationURI: toolURI,
+			Rules:          rules,
+		}},
+		Results: []sarifResult{},
+	}
+
+	invocation := sarifInvocation{ExecutionSuccessful: true}
+	for _, r := range results {
+		if r.Status == processor.StatusError {
+			invocation.ExecutionSuccessful = false
+			invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, sarifNotification{
+				Level:     "error",
+				Message:   sarifMessage{Text: errorMessage(r)},
+				Locations: []sarifLocation{location(r)},
+			})
+			continue
+		}
+
+		ruleID, message := finding(r)
+		if ruleID == "" {
+			continue
+		}
+		index := ruleIndex(ruleID)
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    ruleID,
+			RuleIndex: index,
+			Level:     rules[index].DefaultConfiguration.Level,
+			Message:   sarifMessage{Text: message},
+			Locations: []sarifLocation{location(r)},
+		})
+	}
+	run.Invocations = []sarifInvocation{invocation}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
+
+// finding returns the rule a result violates and a message describing it, or an
+// empty rule ID if the result is not a finding.
+func finding(r processor.Result) (string, string) {
+	name := actionName(r)
+
+	switch r.Status {
+	case processor.StatusChanged:
+		switch {
+		case r.Image:
+			if r.OldRef != "" {
+				name += ":" + r.OldRef
+			}
+			digest := r.NewRef[strings.LastIndex(r.NewRef, "@")+1:]
+			return RuleUnpinnedImage, fmt.Sprintf("Image %s is not pinned to a digest. Its tag currently points at %s.", name, digest)
+		case isSHA(r.OldRef):
+			return RuleOutdatedPin, fmt.Sprintf("%s is pinned to %s, but %s is available at %s.", name, r.OldRef, r.NewVersion, r.NewRef)
+		case r.NewVersion != "":
+			return RuleUnpinnedAction, fmt.Sprintf("%s@%s is not pinned to a commit SHA. Pin it to %s (%s).", name, r.OldRef, r.NewRef, r.NewVersion)
+		default:
+			return RuleUnpinnedAction, fmt.Sprintf("%s@%s is not pinned to a commit SHA.", name, r.OldRef)
+		}
+	case processor.StatusMismatch:
+		return RuleCommentMismatch, fmt.Sprintf("%s is pinned to %s, but the version comment %s points at %s.", name, r.OldRef, r.Version, r.ExpectedRef)
+	case processor.StatusUntagged:
+		return RuleUntaggedCommit, fmt.Sprintf("%s is pinned to %s, which no tag points at.", name, r.OldRef)
+	case processor.StatusFork:
+		return RuleForkCommit, fmt.Sprintf("%s is pinned to %s, which is not reachable from the repository and may come from a fork.", name, r.OldRef)
+	default:
+		return "", ""
+	}
+}
+
+func errorMessage(r processor.Result) string {
+	if r.Action == "" {
+		return fmt.Sprintf("Failed to process %s: %s", r.File, r.Error)
+	}
+	return fmt.Sprintf("Failed to resolve %s: %s", actionName(r), r.Error)
+}
+
+// actionName returns the name of the action as written in the file, including any subpath.
+func actionName(r processor.Result) string {
+	if r.Subpath != "" {
+		return r.Action + "/" + r.Subpath
+	}
+	return r.Action
+}
+
+func location(r processor.Result) sarifLocation {
+	loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: artifactURI(r.File)},
+	}}
+	if r.Line > 0 {
+		loc.PhysicalLocation.Region = &sarifRegion{
+			StartLine:   r.Line,
+			StartColumn: r.Column,
+			EndColumn:   r.EndColumn,
+		}
+	}
+	return loc
+}
+
+// artifactURI returns the path of a file relative to the working directory, using
+// forward slashes, so that code scanning can match it to the file in the repository.
+func artifactURI(path string) string {
+	if filepath.IsAbs(path) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+func ruleIndex(id string) int {
+	for i, rule := range rules {
+		if rule.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func isSHA(ref string) bool {
+	if len(ref) != 40 {
+		return false
+	}
+	for _, c := range ref {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/behnh/actions-toolkit/internal/processor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	sha := "d4323d4df104b026a6aa633fdb11d772146be0bf"
+	results := []processor.Result{
+		{File: "ci.yaml", Line: 5, Column: 15, EndColumn: 34, Action: "actions/checkout", OldRef: "v4", NewRef: sha, NewVersion: "v4.2.2", Status: processor.StatusChanged},
+		{File: "ci.yaml", Line: 6, Column: 15, EndColumn: 72, Action: "actions/cache", Subpath: "save", OldRef: "0c45773b623bea8c8e75f6c82b208c3cf94ea4f9", NewRef: sha, NewVersion: "v4.2.2", Status: processor.StatusChanged},
+		{File: "ci.yaml", Line: 7, Column: 15, EndColumn: 30, Image: true, Action: "postgres", OldRef: "16", NewRef: "16@sha256:bbbb", NewVersion: "16", Status: processor.StatusChanged},
+		{File: "ci.yaml", Line: 8, Column: 15, EndColumn: 74, Action: "actions/setup-go", OldRef: sha, Version: "v5.0.0", ExpectedRef: "0c45773b623bea8c8e75f6c82b208c3cf94ea4f9", Status: processor.StatusMismatch},
+		{File: "ci.yaml", Line: 9, Column: 15, EndColumn: 74, Action: "actions/setup-node", OldRef: sha, Status: processor.StatusOK},
+		{File: "broken.yaml", Status: processor.StatusError, Error: "yaml: invalid"},
+	}
+
+	var buf bytes.Buffer
+	err := WriteSARIF(&buf, "1.2.3", results)
+	require.NoError(t, err)
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+
+	run := log.Runs[0]
+	assert.Equal(t, "actions-toolkit", run.Tool.Driver.Name)
+	assert.Equal(t, "1.2.3", run.Tool.Driver.Version)
+	assert.Len(t, run.Tool.Driver.Rules, len(rules))
+
+	var ruleIDs []string
+	for _, r := range run.Results {
+		ruleIDs = append(ruleIDs, r.RuleID)
+		assert.Equal(t, r.RuleID, run.Tool.Driver.Rules[r.RuleIndex].ID)
+	}
+	assert.Equal(t, []string{RuleUnpinnedAction, RuleOutdatedPin, RuleUnpinnedImage, RuleCommentMismatch}, ruleIDs)
+
+	assert.Equal(t, "ci.yaml", run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, &sarifRegion{StartLine: 5, StartColumn: 15, EndColumn: 34}, run.Results[0].Locations[0].PhysicalLocation.Region)
+	assert.Equal(t, "warning", run.Results[0].Level)
+	assert.Contains(t, run.Results[1].Message.Text, "actions/cache/save")
+	assert.Contains(t, run.Results[2].Message.Text, "postgres:16")
+	assert.Equal(t, "error", run.Results[3].Level)
+
+	require.Len(t, run.Invocations, 1)
+	assert.False(t, run.Invocations[0].ExecutionSuccessful)
+	require.Len(t, run.Invocations[0].ToolExecutionNotifications, 1)
+	assert.Nil(t, run.Invocations[0].ToolExecutionNotifications[0].Locations[0].PhysicalLocation.Region)
+}
+
+func TestWriteSARIFNoFindings(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteSARIF(&buf, "1.2.3", nil)
+	require.NoError(t, err)
+
+	var log map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	runs := log["runs"].([]any)
+	run := runs[0].(map[string]any)
+	assert.Equal(t, []any{}, run["results"])
+	assert.Equal(t, true, run["invocations"].([]any)[0].(map[string]any)["executionSuccessful"])
+}
@@ -0,0 +1,199 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a release version such as v4.2.1 or v5.0.0-beta.1. Versions may leave out
+// the minor and patch numbers (e.g., v4 or v4.2), which are then treated as zero.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+	// Parts is the number of numeric parts given, from 1 for v4 to 3 for v4.2.1
+	Parts int
+	// Prefix is true if the version was written with a leading 'v'
+	Prefix bool
+	// Prerelease is the prerelease suffix without its '-' (e.g., beta.1), empty for releases
+	Prerelease string
+	// Build is the build metadata without its '+', which is ignored when comparing versions
+	Build string
+}
+
+// Parse parses a version such as v4, 4.2, v4.2.1, v5.0.0-rc.1 or v1.0.0+20250101. ok is
+// false for anything else, including SHAs and branch names.
+func Parse(s string) (Version, bool) {
+	var v Version
+	if strings.HasPrefix(s, "v") {
+		v.Prefix = true
+		s = s[1:]
+	}
+
+	if i := strings.Index(s, "+"); i >= 0 {
+		v.Build = s[i+1:]
+		s = s[:i]
+		if !validIdentifiers(v.Build) {
+			return Version{}, false
+		}
+	}
+	if i := strings.Index(s, "-"); i >= 0 {
+		v.Prerelease = s[i+1:]
+		s = s[:i]
+		if !validIdentifiers(v.Prerelease) {
+			return Version{}, false
+		}
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return Version{}, false
+	}
+
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		if part == "" || strings.Trim(part, "0123456789") != "" {
+			return Version{}, false
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return Version{}, false
+		}
+		numbers[i] = n
+	}
+
+	v.Major, v.Minor, v.Patch = numbers[0], numbers[1], numbers[2]
+	v.Parts = len(parts)
+	return v, true
+}
+
+// validIdentifiers checks that a prerelease or build suffix consists of dot-separated,
+// non-empty identifiers of ASCII letters, digits and hyphens.
+func validIdentifiers(s string) bool {
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return false
+		}
+		for _, c := range id {
+			if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsPrerelease reports whether the version has a prerelease suffix.
+func (v Version) IsPrerelease() bool {
+	return v.Prerelease != ""
+}
+
+// Compare returns -1, 0 or 1 depending on whether a is lower than, equal to or
+// higher than b. Missing parts count as zero, so v4 and v4.0.0 are equal. A prerelease
+// is lower than the release it precedes (v5.0.0-rc.1 < v5.0.0), and prereleases of the
+// same version are ordered by their identifiers as in Semantic Versioning 2.0.0.
+// Build metadata is ignored.
+func Compare(a, b Version) int {
+	switch {
+	case a.Major != b.Major:
+		return compareInts(a.Major, b.Major)
+	case a.Minor != b.Minor:
+		return compareInts(a.Minor, b.Minor)
+	case a.Patch != b.Patch:
+		return compareInts(a.Patch, b.Patch)
+	default:
+		return comparePrereleases(a.Prerelease, b.Prerelease)
+	}
+}
+
+// comparePrereleases compares two prerelease suffixes. An empty suffix, which is a
+// release, is higher than any prerelease. Numeric identifiers are compared as numbers
+// and are lower than alphanumeric ones, which are compared lexically. If all identifiers
+// are equal, the suffix with more identifiers is higher.
+func comparePrereleases(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return compareInts(an, bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		case as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+	return compareInts(len(as), len(bs))
+}
+
+// String formats the version with the same prefix, number of parts and suffixes it was
+// parsed with.
+func (v Version) String() string {
+	prefix := ""
+	if v.Prefix {
+		prefix = "v"
+	}
+	var s string
+	switch v.Parts {
+	case 1:
+		s = fmt.Sprintf("%s%d", prefix, v.Major)
+	case 2:
+		s = fmt.Sprintf("%s%d.%d", prefix, v.Major, v.Minor)
+	default:
+		s = fmt.Sprintf("%s%d.%d.%d", prefix, v.Major, v.Minor, v.Patch)
+	}
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// MajorTag returns the major version tag that floats along with the version, such as
+// v4 for v4.2.1, keeping its prefix.
+func (v Version) MajorTag() string {
+	return Version{Major: v.Major, Parts: 1, Prefix: v.Prefix}.String()
+}
+
+func compareInts(a, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,105 @@
+/*
+Copyright © 2025 Behn Hayhoe hello@behn.dev
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package semver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Version
+		ok       bool
+	}{
+		{"v4", Version{Major: 4, Parts: 1, Prefix: true}, true},
+		{"4.2", Version{Major: 4, Minor: 2, Parts: 2}, true},
+		{"v4.2.1", Version{Major: 4, Minor: 2, Patch: 1, Parts: 3, Prefix: true}, true},
+		{"v10.0.12", Version{Major: 10, Patch: 12, Parts: 3, Prefix: true}, true},
+		{"v4.2.1.0", Version{}, false},
+		{"v4.2.1-beta.1", Version{Major: 4, Minor: 2, Patch: 1, Parts: 3, Prefix: true, Prerelease: "beta.1"}, true},
+		{"5.0.0-rc.1+build.7", Version{Major: 5, Parts: 3, Prerelease: "rc.1", Build: "build.7"}, true},
+		{"v1.0.0+20250101", Version{Major: 1, Parts: 3, Prefix: true, Build: "20250101"}, true},
+		{"v4.2.1-", Version{}, false},
+		{"v4.2.1-beta..1", Version{}, false},
+		{"v4.2.1+", Version{}, false},
+		{"v4.2.1-beta_1", Version{}, false},
+		{"v4..1", Version{}, false},
+		{"main", Version{}, false},
+		{"v", Version{}, false},
+		{"", Version{}, false},
+		{"8f4b7f84864484a7bf31766abe9204da3cbe65b3", Version{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := Parse(tt.input)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"v4.2.1", "v4.2.1", 0},
+		{"v4", "v4.0.0", 0},
+		{"v4.2.1", "v4.10.0", -1},
+		{"v5", "v4.99.99", 1},
+		{"v4.2.10", "v4.2.9", 1},
+		{"v5.0.0-rc.1", "v5.0.0", -1},
+		{"v5.0.0-rc.1", "v4.9.9", 1},
+		{"v5.0.0-alpha", "v5.0.0-alpha.1", -1},
+		{"v5.0.0-alpha.1", "v5.0.0-alpha.beta", -1},
+		{"v5.0.0-beta.2", "v5.0.0-beta.11", -1},
+		{"v5.0.0-beta.11", "v5.0.0-rc.1", -1},
+		{"v5.0.0+build.1", "v5.0.0+build.2", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			a, _ := Parse(tt.a)
+			b, _ := Parse(tt.b)
+			assert.Equal(t, tt.expected, Compare(a, b))
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	for _, s := range []string{"v4", "4.2", "v4.2.1", "v5.0.0-beta.1", "1.0.0+build.5"} {
+		v, ok := Parse(s)
+		assert.True(t, ok)
+		assert.Equal(t, s, v.String())
+	}
+}
+
+func TestMajorTag(t *testing.T) {
+	for input, expected := range map[string]string{
+		"v4.2.1":        "v4",
+		"4.2":           "4",
+		"v5.0.0-beta.1": "v5",
+	} {
+		v, ok := Parse(input)
+		assert.True(t, ok)
+		assert.Equal(t, expected, v.MajorTag())
+	}
+}